@@ -0,0 +1,97 @@
+// Package apierror classifies failed calls to the web APIs Kira depends
+// on, so the user can be told whether to fix a key, wait or try again.
+package apierror
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrAuth matches answers that reject the API key.
+	ErrAuth = errors.New("authentication failed")
+	// ErrRateLimited matches answers asking to slow down, or saying the
+	// account has run out of quota.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrUnavailable matches server errors on the API side.
+	ErrUnavailable = errors.New("service unavailable")
+	// ErrTimeout matches requests that took too long.
+	ErrTimeout = errors.New("request timed out")
+)
+
+// StatusError is an HTTP answer outside the 2xx range. errors.Is matches it
+// against ErrAuth, ErrRateLimited or ErrUnavailable depending on the code.
+type StatusError struct {
+	Service    string
+	StatusCode int
+	// Message is the error the API gave in the body, if any.
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%s returned status %d", e.Service, e.StatusCode)
+	}
+	return fmt.Sprintf("%s returned status %d: %s", e.Service, e.StatusCode, e.Message)
+}
+
+func (e *StatusError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+		return ErrAuth
+	case e.StatusCode == http.StatusTooManyRequests || e.StatusCode == http.StatusPaymentRequired:
+		return ErrRateLimited
+	case e.StatusCode >= 500:
+		return ErrUnavailable
+	}
+	return nil
+}
+
+// Check returns a *StatusError when res has a status outside 2xx. It reads
+// the body to find the error message but does not close it.
+func Check(service string, res *http.Response) error {
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+	return &StatusError{Service: service, StatusCode: res.StatusCode, Message: message(body)}
+}
+
+// message picks the error out of a JSON body such as {"error": "..."},
+// falling back to the body itself.
+func message(body []byte) string {
+	var fields struct {
+		Error   interface{} `json:"error"`
+		Message string      `json:"message"`
+		Reason  string      `json:"reason"`
+	}
+	if json.Unmarshal(body, &fields) == nil {
+		if text, ok := fields.Error.(string); ok && text != "" {
+			return text
+		}
+		if fields.Message != "" {
+			return fields.Message
+		}
+		if fields.Reason != "" {
+			return fields.Reason
+		}
+	}
+	return strings.TrimSpace(string(body))
+}
+
+// Transport wraps the error of a request that got no answer, matching
+// ErrTimeout when it took too long.
+func Transport(service string, err error) error {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout() {
+		return fmt.Errorf("%s: %w: %w", service, ErrTimeout, err)
+	}
+	return fmt.Errorf("error calling %s: %w", service, err)
+}
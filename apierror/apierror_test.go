@@ -0,0 +1,104 @@
+package apierror_test
+
+import (
+	"KevinGo/apierror"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestStatusError(t *testing.T) {
+	sentinels := []error{apierror.ErrAuth, apierror.ErrRateLimited, apierror.ErrUnavailable, apierror.ErrTimeout}
+
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{http.StatusBadRequest, nil},
+		{http.StatusUnauthorized, apierror.ErrAuth},
+		{http.StatusPaymentRequired, apierror.ErrRateLimited},
+		{http.StatusForbidden, apierror.ErrAuth},
+		{http.StatusNotFound, nil},
+		{http.StatusTooManyRequests, apierror.ErrRateLimited},
+		{http.StatusInternalServerError, apierror.ErrUnavailable},
+		{http.StatusBadGateway, apierror.ErrUnavailable},
+		{http.StatusServiceUnavailable, apierror.ErrUnavailable},
+	}
+
+	for _, tt := range tests {
+		err := error(&apierror.StatusError{Service: "Test", StatusCode: tt.status})
+		for _, sentinel := range sentinels {
+			if got := errors.Is(err, sentinel); got != (sentinel == tt.want) {
+				t.Errorf("%d: errors.Is(%v) = %v", tt.status, sentinel, got)
+			}
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		want   string
+	}{
+		{http.StatusOK, `{"error": "ignored"}`, ""},
+		{http.StatusNoContent, "", ""},
+		{http.StatusUnauthorized, `{"error": "Invalid API key"}`, "Test returned status 401: Invalid API key"},
+		{http.StatusUnauthorized, `{"cod": 401, "message": "Invalid API key."}`, "Test returned status 401: Invalid API key."},
+		{http.StatusBadRequest, `{"error": true, "reason": "Latitude must be in range"}`, "Test returned status 400: Latitude must be in range"},
+		{http.StatusBadGateway, "  <html>Bad Gateway</html>\n", "Test returned status 502: <html>Bad Gateway</html>"},
+		{http.StatusServiceUnavailable, "", "Test returned status 503"},
+	}
+
+	for _, tt := range tests {
+		res := &http.Response{StatusCode: tt.status, Body: io.NopCloser(strings.NewReader(tt.body))}
+		err := apierror.Check("Test", res)
+
+		if tt.want == "" {
+			if err != nil {
+				t.Errorf("%d: Check = %v, want nil", tt.status, err)
+			}
+			continue
+		}
+
+		var statusErr *apierror.StatusError
+		if !errors.As(err, &statusErr) || statusErr.StatusCode != tt.status {
+			t.Errorf("%d: Check = %v, want a StatusError", tt.status, err)
+			continue
+		}
+		if err.Error() != tt.want {
+			t.Errorf("%d: Check = %q, want %q", tt.status, err, tt.want)
+		}
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestTransport(t *testing.T) {
+	tests := []struct {
+		err     error
+		timeout bool
+	}{
+		{context.DeadlineExceeded, true},
+		{timeoutError{}, true},
+		{context.Canceled, false},
+		{errors.New("connection refused"), false},
+	}
+
+	for _, tt := range tests {
+		err := apierror.Transport("Test", tt.err)
+		if errors.Is(err, apierror.ErrTimeout) != tt.timeout {
+			t.Errorf("Transport(%v) = %v, want timeout %v", tt.err, err, tt.timeout)
+		}
+		if !errors.Is(err, tt.err) {
+			t.Errorf("Transport(%v) = %v, want it to wrap the cause", tt.err, err)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package audio
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SpeechRate is the sample rate speech recognizers work at. Recordings
+// are brought down to it before they are encoded, which also keeps
+// uploads small.
+const SpeechRate = 16000
+
+// Format is an encoding the package can produce.
+type Format string
+
+const (
+	WAV  Format = "wav"
+	FLAC Format = "flac"
+)
+
+// ParseFormat accepts a format name as written in the configuration.
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimPrefix(name, "."))); f {
+	case WAV, FLAC:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unsupported audio format %q (use wav or flac)", name)
+	}
+}
+
+// Ext is the file extension for the format, without the dot.
+func (f Format) Ext() string {
+	return string(f)
+}
+
+func (f Format) ContentType() string {
+	return ContentType("." + string(f))
+}
+
+// ContentType returns the MIME type for a file name or extension. Unknown
+// extensions are sent as raw bytes and left to the receiver to detect.
+func ContentType(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".wav":
+		return "audio/wav"
+	case ".flac":
+		return "audio/flac"
+	case ".m4a":
+		return "audio/mp4"
+	case ".mp3":
+		return "audio/mpeg"
+	case ".aiff", ".aif":
+		return "audio/aiff"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// Recording is audio captured from the microphone, kept in memory.
+type Recording struct {
+	// Samples are interleaved when there is more than one channel.
+	Samples    []int16
+	SampleRate int
+	Channels   int
+}
+
+func (r Recording) Duration() time.Duration {
+	if r.SampleRate <= 0 || r.Channels <= 0 {
+		return 0
+	}
+	return time.Duration(len(r.Samples)/r.Channels) * time.Second / time.Duration(r.SampleRate)
+}
+
+// ForSpeech returns the recording as 16 kHz mono.
+func (r Recording) ForSpeech() Recording {
+	return Recording{
+		Samples:    ForSpeech(r.Samples, r.SampleRate, r.Channels),
+		SampleRate: SpeechRate,
+		Channels:   1,
+	}
+}
+
+// Write encodes the recording in the given format.
+func (r Recording) Write(w io.Writer, f Format) error {
+	switch f {
+	case WAV:
+		return WriteWAV(w, r.Samples, r.SampleRate, r.Channels)
+	case FLAC:
+		return WriteFLAC(w, r.Samples, r.SampleRate, r.Channels)
+	default:
+		return fmt.Errorf("unsupported audio format %q", f)
+	}
+}
+
+// Encode returns the recording encoded in the given format.
+func (r Recording) Encode(f Format) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := r.Write(&buf, f); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Stream encodes the recording in the background while the returned
+// reader is consumed, for example by an upload. Closing the reader early
+// stops the encoder.
+func (r Recording) Stream(f Format) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(r.Write(pw, f))
+	}()
+	return pr
+}
+
+// ForSpeech downmixes interleaved samples to mono and resamples them to
+// SpeechRate.
+func ForSpeech(samples []int16, sampleRate, channels int) []int16 {
+	return Resample(Downmix(samples, channels), sampleRate, SpeechRate)
+}
+
+// Downmix averages the channels of interleaved samples into one.
+func Downmix(samples []int16, channels int) []int16 {
+	if channels <= 1 {
+		return samples
+	}
+
+	mono := make([]int16, len(samples)/channels)
+	for i := range mono {
+		sum := 0
+		for _, s := range samples[i*channels : (i+1)*channels] {
+			sum += int(s)
+		}
+		mono[i] = int16(sum / channels)
+	}
+	return mono
+}
+
+// Resample converts mono samples from one rate to another. When the rate
+// goes down, each output sample averages the input it covers, which
+// filters out most of what the lower rate cannot represent; otherwise it
+// interpolates linearly between neighbours.
+func Resample(samples []int16, from, to int) []int16 {
+	if from == to || from <= 0 || to <= 0 || len(samples) == 0 {
+		return samples
+	}
+
+	n := int(int64(len(samples)) * int64(to) / int64(from))
+	out := make([]int16, n)
+	step := float64(from) / float64(to)
+
+	for i := range out {
+		pos := float64(i) * step
+
+		if step > 1 {
+			start := int(pos)
+			end := min(int(pos+step), len(samples))
+			sum := 0
+			for _, s := range samples[start:end] {
+				sum += int(s)
+			}
+			out[i] = int16(sum / max(end-start, 1))
+			continue
+		}
+
+		j := int(pos)
+		frac := pos - float64(j)
+		next := samples[min(j+1, len(samples)-1)]
+		out[i] = int16(float64(samples[j])*(1-frac) + float64(next)*frac)
+	}
+
+	return out
+}
+
+// Resampler converts mono samples that arrive in pieces, such as the
+// chunks of a clip being played. It keeps its place between calls, so the
+// pieces join up exactly as if the whole stream had gone through
+// Resample; the last sample or two of a piece wait for the next one.
+type Resampler struct {
+	From, To int
+
+	produced int     // output samples returned so far
+	consumed int     // input samples no longer needed
+	pending  []int16 // input from consumed on
+}
+
+// Resample returns the output that samples complete.
+func (r *Resampler) Resample(samples []int16) []int16 {
+	if r.From == r.To || r.From <= 0 || r.To <= 0 {
+		return samples
+	}
+
+	in := append(r.pending, samples...)
+	step := float64(r.From) / float64(r.To)
+	var out []int16
+
+	for {
+		pos := float64(r.produced) * step
+		j := int(pos) - r.consumed
+
+		if step > 1 {
+			end := int(pos+step) - r.consumed
+			if end > len(in) {
+				break
+			}
+			sum := 0
+			for _, s := range in[j:end] {
+				sum += int(s)
+			}
+			out = append(out, int16(sum/max(end-j, 1)))
+		} else {
+			if j+1 >= len(in) {
+				break
+			}
+			frac := pos - float64(int(pos))
+			out = append(out, int16(float64(in[j])*(1-frac)+float64(in[j+1])*frac))
+		}
+		r.produced++
+	}
+
+	keep := min(int(float64(r.produced)*step)-r.consumed, len(in))
+	r.pending = append(r.pending[:0], in[keep:]...)
+	r.consumed += keep
+	return out
+}
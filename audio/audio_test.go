@@ -0,0 +1,127 @@
+package audio_test
+
+import (
+	"KevinGo/audio"
+	"reflect"
+	"testing"
+)
+
+func TestResample(t *testing.T) {
+	tests := []struct {
+		name     string
+		samples  []int16
+		from, to int
+		want     []int16
+	}{
+		{"same rate", []int16{1, 2, 3}, 16000, 16000, []int16{1, 2, 3}},
+		{"down by three averages", []int16{0, 1, 2, 3, 4, 5, 60, 60, 60}, 48000, 16000, []int16{1, 4, 60}},
+		{"down by two averages", []int16{-100, 100, 10, 30}, 32000, 16000, []int16{0, 20}},
+		{"up by two interpolates", []int16{0, 100, -100}, 8000, 16000, []int16{0, 50, 100, 0, -100, -100}},
+		{"empty", nil, 44100, 16000, nil},
+	}
+
+	for _, tt := range tests {
+		if got := audio.Resample(tt.samples, tt.from, tt.to); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Resample = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestResampleLength(t *testing.T) {
+	tests := []struct {
+		n, from, to, want int
+	}{
+		{44100, 44100, 16000, 16000},
+		{1000, 44100, 16000, 362},
+		{4800, 48000, 16000, 1600},
+		{160, 16000, 44100, 441},
+		{7, 22050, 16000, 5},
+	}
+
+	for _, tt := range tests {
+		if got := len(audio.Resample(make([]int16, tt.n), tt.from, tt.to)); got != tt.want {
+			t.Errorf("%d samples %d→%d Hz: got %d, want %d", tt.n, tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+// A constant signal must stay constant whichever way it is resampled.
+func TestResampleKeepsLevel(t *testing.T) {
+	for _, rates := range [][2]int{{44100, 16000}, {16000, 44100}, {48000, 22050}} {
+		for i, s := range audio.Resample(constant(1000, -1234), rates[0], rates[1]) {
+			if s != -1234 {
+				t.Fatalf("%d→%d Hz: sample %d = %d, want -1234", rates[0], rates[1], i, s)
+			}
+		}
+	}
+}
+
+// Resampling a clip piece by piece must give what resampling it whole
+// does, whatever size the pieces are.
+func TestResamplerMatchesResample(t *testing.T) {
+	samples := sine(5000, 440, 22050, 20000)
+	for _, rates := range [][2]int{{22050, 48000}, {24000, 48000}, {48000, 16000}, {44100, 16000}} {
+		whole := audio.Resample(samples, rates[0], rates[1])
+
+		r := &audio.Resampler{From: rates[0], To: rates[1]}
+		var pieces []int16
+		for i, size := 0, 1; i < len(samples); i, size = i+size, size*3%97+1 {
+			pieces = append(pieces, r.Resample(samples[i:min(i+size, len(samples))])...)
+		}
+
+		if len(pieces) < len(whole)-2 || len(pieces) > len(whole) {
+			t.Fatalf("%d→%d Hz: %d samples, want about %d", rates[0], rates[1], len(pieces), len(whole))
+		}
+		if !reflect.DeepEqual(pieces, whole[:len(pieces)]) {
+			t.Errorf("%d→%d Hz: pieces differ from resampling the whole clip", rates[0], rates[1])
+		}
+	}
+}
+
+func TestDownmix(t *testing.T) {
+	tests := []struct {
+		name     string
+		samples  []int16
+		channels int
+		want     []int16
+	}{
+		{"mono unchanged", []int16{1, -2, 3}, 1, []int16{1, -2, 3}},
+		{"stereo averages", []int16{100, 200, -100, -300, 32767, 32767}, 2, []int16{150, -200, 32767}},
+		{"three channels", []int16{3, 6, 9, -3, 0, 0}, 3, []int16{6, -1}},
+		{"opposite phases cancel", []int16{-32768, 32767}, 2, []int16{0}},
+	}
+
+	for _, tt := range tests {
+		if got := audio.Downmix(tt.samples, tt.channels); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Downmix = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRecordingForSpeech(t *testing.T) {
+	rec := audio.Recording{Samples: make([]int16, 2*48000), SampleRate: 48000, Channels: 2}
+	speech := rec.ForSpeech()
+	if speech.SampleRate != audio.SpeechRate || speech.Channels != 1 || len(speech.Samples) != audio.SpeechRate {
+		t.Errorf("ForSpeech = %d Hz, %d channels, %d samples, want one second of 16 kHz mono",
+			speech.SampleRate, speech.Channels, len(speech.Samples))
+	}
+	if speech.Duration() != rec.Duration() {
+		t.Errorf("duration changed from %v to %v", rec.Duration(), speech.Duration())
+	}
+}
+
+func TestWAVRoundTrip(t *testing.T) {
+	rec := audio.Recording{Samples: interleave(sine(3000, 440, 22050, 9000), noise(3000, 500)), SampleRate: 22050, Channels: 2}
+	data, err := rec.Encode(audio.WAV)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	samples, rate, channels, err := audio.DecodeWAV(data)
+	if err != nil {
+		t.Fatalf("DecodeWAV: %v", err)
+	}
+	if rate != 22050 || channels != 2 || !reflect.DeepEqual(samples, rec.Samples) {
+		t.Errorf("decoded %d Hz, %d channels, equal samples %v", rate, channels, reflect.DeepEqual(samples, rec.Samples))
+	}
+}
@@ -0,0 +1,324 @@
+package audio
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// flacBlockSize is the number of samples per channel in each frame, the
+// size the reference encoder uses at its default settings.
+const flacBlockSize = 4096
+
+// WriteFLAC writes interleaved samples as 16-bit FLAC. Each channel is
+// coded with the best of the format's fixed predictors and Rice-coded
+// residuals, which is lossless and roughly halves the size of speech
+// compared to WAV. Frames are written as soon as they are encoded, so
+// the stream can be uploaded while it is produced; the frame sizes in
+// the header are therefore left unknown.
+func WriteFLAC(out io.Writer, samples []int16, sampleRate, channels int) error {
+	if channels < 1 || channels > 8 {
+		return fmt.Errorf("FLAC supports 1 to 8 channels, got %d", channels)
+	}
+	if sampleRate <= 0 || sampleRate >= 1<<20 {
+		return fmt.Errorf("invalid FLAC sample rate %d", sampleRate)
+	}
+
+	total := len(samples) / channels
+	samples = samples[:total*channels]
+
+	// STREAMINFO, the only metadata block, describes the whole stream.
+	raw := new(bytes.Buffer)
+	binary.Write(raw, binary.LittleEndian, samples)
+	sum := md5.Sum(raw.Bytes())
+
+	w := &bitWriter{}
+	w.write(1, 1) // last metadata block
+	w.write(0, 7) // STREAMINFO
+	w.write(34, 24)
+	w.write(flacBlockSize, 16)
+	w.write(flacBlockSize, 16)
+	w.write(0, 24) // minimum frame size, unknown
+	w.write(0, 24) // maximum frame size, unknown
+	w.write(uint64(sampleRate), 20)
+	w.write(uint64(channels-1), 3)
+	w.write(15, 5) // 16 bits per sample
+	w.write(uint64(total), 36)
+
+	header := append([]byte("fLaC"), w.buf...)
+	if _, err := out.Write(append(header, sum[:]...)); err != nil {
+		return err
+	}
+
+	channel := make([]int32, flacBlockSize)
+	for number, start := 0, 0; start < total; number, start = number+1, start+flacBlockSize {
+		size := min(flacBlockSize, total-start)
+
+		w := &bitWriter{}
+		writeFrameHeader(w, number, size, channels)
+		for c := 0; c < channels; c++ {
+			for i := 0; i < size; i++ {
+				channel[i] = int32(samples[(start+i)*channels+c])
+			}
+			writeSubframe(w, channel[:size])
+		}
+		w.align()
+		crc := crc16(w.buf)
+		w.write(uint64(crc), 16)
+
+		if _, err := out.Write(w.buf); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeFrameHeader(w *bitWriter, number, size, channels int) {
+	w.write(0xFFF8, 16) // sync code, fixed block size
+	w.write(0x7, 4)     // block size follows as 16 bits
+	w.write(0x0, 4)     // sample rate from STREAMINFO
+	w.write(uint64(channels-1), 4)
+	w.write(0x4, 3) // 16 bits per sample
+	w.write(0, 1)
+	writeUTF8(w, uint64(number))
+	w.write(uint64(size-1), 16)
+	w.write(uint64(crc8(w.buf)), 8)
+}
+
+// writeUTF8 writes the frame number in the UTF-8-like coding FLAC uses.
+func writeUTF8(w *bitWriter, v uint64) {
+	if v < 0x80 {
+		w.write(v, 8)
+		return
+	}
+
+	n := 2
+	for v >= 1<<(5*n+1) {
+		n++
+	}
+	w.write((0xFF<<(8-n))&0xFF|v>>(6*(n-1)), 8)
+	for i := n - 2; i >= 0; i-- {
+		w.write(0x80|(v>>(6*i))&0x3F, 8)
+	}
+}
+
+// writeSubframe codes one channel of a frame as a constant, with a fixed
+// predictor, or verbatim, whichever is smallest.
+func writeSubframe(w *bitWriter, x []int32) {
+	constant := true
+	for _, s := range x[1:] {
+		if s != x[0] {
+			constant = false
+			break
+		}
+	}
+	if constant {
+		w.write(0, 8)
+		w.write(uint64(x[0]), 16)
+		return
+	}
+
+	bestOrder, bestPartition := -1, 0
+	bestBits := 16 * len(x) // verbatim
+	var bestResidual []int32
+
+	for order := 0; order <= 4 && order < len(x); order++ {
+		residual := fixedResidual(x, order)
+		partitionOrder, bits := riceCost(residual, len(x), order)
+		bits += 16*order + 6
+		if bits < bestBits {
+			bestOrder, bestPartition, bestBits = order, partitionOrder, bits
+			bestResidual = residual
+		}
+	}
+
+	if bestOrder < 0 {
+		w.write(0x02, 8) // verbatim
+		for _, s := range x {
+			w.write(uint64(s), 16)
+		}
+		return
+	}
+
+	w.write(uint64(0x10|bestOrder<<1), 8) // fixed predictor
+	for _, s := range x[:bestOrder] {
+		w.write(uint64(s), 16)
+	}
+	writeResidual(w, bestResidual, len(x), bestOrder, bestPartition)
+}
+
+// fixedResidual is what is left of x after the fixed polynomial predictor
+// of the given order, for every sample after the warm-up.
+func fixedResidual(x []int32, order int) []int32 {
+	residual := make([]int32, len(x)-order)
+	for i := order; i < len(x); i++ {
+		var prediction int32
+		switch order {
+		case 1:
+			prediction = x[i-1]
+		case 2:
+			prediction = 2*x[i-1] - x[i-2]
+		case 3:
+			prediction = 3*x[i-1] - 3*x[i-2] + x[i-3]
+		case 4:
+			prediction = 4*x[i-1] - 6*x[i-2] + 4*x[i-3] - x[i-4]
+		}
+		residual[i-order] = x[i] - prediction
+	}
+	return residual
+}
+
+// partitions returns the residual split into 2^partitionOrder parts. The
+// first part is shorter by the predictor order, whose warm-up samples are
+// stored verbatim.
+func partitions(residual []int32, blockSize, order, partitionOrder int) [][]int32 {
+	size := blockSize >> partitionOrder
+	parts := make([][]int32, 0, 1<<partitionOrder)
+	start := 0
+	for p := 0; p < 1<<partitionOrder; p++ {
+		end := start + size
+		if p == 0 {
+			end -= order
+		}
+		parts = append(parts, residual[start:end])
+		start = end
+	}
+	return parts
+}
+
+// riceCost picks the partition order that codes the residual in the
+// fewest bits and returns it with that size, headers included.
+func riceCost(residual []int32, blockSize, order int) (int, int) {
+	bestOrder, bestBits := 0, -1
+	for partitionOrder := 0; partitionOrder <= 8; partitionOrder++ {
+		size := blockSize >> partitionOrder
+		if blockSize%(1<<partitionOrder) != 0 || size <= order && partitionOrder > 0 {
+			break
+		}
+
+		bits := 6
+		for _, part := range partitions(residual, blockSize, order, partitionOrder) {
+			_, partBits := riceParameter(part)
+			bits += 4 + partBits
+		}
+		if bestBits < 0 || bits < bestBits {
+			bestOrder, bestBits = partitionOrder, bits
+		}
+	}
+	return bestOrder, bestBits
+}
+
+// riceParameter returns the Rice parameter that codes part in the fewest
+// bits, and that number of bits. The best parameter is close to the log
+// of the mean value, so only its neighbours are tried.
+func riceParameter(part []int32) (int, int) {
+	var sum uint64
+	for _, e := range part {
+		sum += zigzag(e)
+	}
+	guess := 0
+	for guess < 14 && uint64(len(part))<<(guess+1) <= sum {
+		guess++
+	}
+
+	bestK, bestBits := 0, -1
+	for k := max(guess-1, 0); k <= min(guess+1, 14); k++ {
+		bits := 0
+		for _, e := range part {
+			bits += int(zigzag(e)>>k) + 1 + k
+		}
+		if bestBits < 0 || bits < bestBits {
+			bestK, bestBits = k, bits
+		}
+	}
+	return bestK, bestBits
+}
+
+func writeResidual(w *bitWriter, residual []int32, blockSize, order, partitionOrder int) {
+	w.write(0, 2) // Rice coding with 4-bit parameters
+	w.write(uint64(partitionOrder), 4)
+	for _, part := range partitions(residual, blockSize, order, partitionOrder) {
+		k, _ := riceParameter(part)
+		w.write(uint64(k), 4)
+		for _, e := range part {
+			u := zigzag(e)
+			w.unary(u >> k)
+			w.write(u, uint(k))
+		}
+	}
+}
+
+// zigzag maps signed residuals to unsigned ones: 0, -1, 1, -2 become 0, 1,
+// 2, 3.
+func zigzag(e int32) uint64 {
+	return uint64(uint32(e<<1) ^ uint32(e>>31))
+}
+
+// bitWriter packs values most significant bit first.
+type bitWriter struct {
+	buf  []byte
+	acc  uint64
+	bits uint
+}
+
+// write appends the low n bits of v.
+func (w *bitWriter) write(v uint64, n uint) {
+	for n > 32 {
+		w.write(v>>(n-32), 32)
+		n -= 32
+	}
+	w.acc = w.acc<<n | v&(1<<n-1)
+	w.bits += n
+	for w.bits >= 8 {
+		w.bits -= 8
+		w.buf = append(w.buf, byte(w.acc>>w.bits))
+	}
+}
+
+// unary writes q zeros followed by a one.
+func (w *bitWriter) unary(q uint64) {
+	for ; q >= 32; q -= 32 {
+		w.write(0, 32)
+	}
+	w.write(1, uint(q)+1)
+}
+
+// align pads with zeros to the next byte.
+func (w *bitWriter) align() {
+	if w.bits > 0 {
+		w.write(0, 8-w.bits)
+	}
+}
+
+func crc8(data []byte) byte {
+	var crc byte
+	for _, b := range data {
+		crc ^= b
+		for i := 0; i < 8; i++ {
+			if crc&0x80 != 0 {
+				crc = crc<<1 ^ 0x07
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
+
+func crc16(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x8005
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
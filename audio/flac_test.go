@@ -0,0 +1,500 @@
+package audio_test
+
+import (
+	"KevinGo/audio"
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestFLACRoundTrip(t *testing.T) {
+	tests := []struct {
+		name       string
+		samples    []int16
+		sampleRate int
+		channels   int
+	}{
+		{"empty", nil, 16000, 1},
+		{"one sample", []int16{-1234}, 16000, 1},
+		{"three samples", []int16{5, -7, 9}, 16000, 1},
+		{"silence", make([]int16, 16000), 16000, 1},
+		{"constant", constant(5000, 3210), 16000, 1},
+		{"sine, partial last frame", sine(2*4096+1234, 440, 16000, 12000), 16000, 1},
+		{"full scale", sine(4096, 50, 16000, 32767), 16000, 1},
+		{"noise", noise(5000, 32767), 16000, 1},
+		{"stereo", interleave(sine(10000, 440, 44100, 9000), noise(10000, 2000)), 44100, 2},
+		{"three channels", interleave(constant(3000, -5), sine(3000, 300, 48000, 20000), noise(3000, 100)), 48000, 3},
+		// Frame numbers of 128 and more take two bytes.
+		{"many frames", sine(130*4096+17, 200, 16000, 3000), 16000, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := audio.WriteFLAC(&buf, tt.samples, tt.sampleRate, tt.channels); err != nil {
+				t.Fatalf("WriteFLAC: %v", err)
+			}
+
+			info, decoded, err := decodeFLAC(buf.Bytes())
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+
+			frames := len(tt.samples) / tt.channels
+			want := streamInfo{
+				minBlock:   4096,
+				maxBlock:   4096,
+				sampleRate: tt.sampleRate,
+				channels:   tt.channels,
+				bits:       16,
+				total:      frames,
+				md5:        md5.Sum(le(tt.samples)),
+			}
+			if info != want {
+				t.Errorf("STREAMINFO = %+v, want %+v", info, want)
+			}
+
+			if len(decoded) != len(tt.samples) {
+				t.Fatalf("decoded %d samples, want %d", len(decoded), len(tt.samples))
+			}
+			for i := range decoded {
+				if decoded[i] != tt.samples[i] {
+					t.Fatalf("sample %d = %d, want %d", i, decoded[i], tt.samples[i])
+				}
+			}
+		})
+	}
+}
+
+// The decoder below must notice a damaged stream, or the round trip
+// proves nothing.
+func TestFLACDecoderCatchesCorruption(t *testing.T) {
+	var buf bytes.Buffer
+	if err := audio.WriteFLAC(&buf, sine(5000, 440, 16000, 8000), 16000, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, offset := range []int{45, 60, buf.Len() / 2, buf.Len() - 1} {
+		data := bytes.Clone(buf.Bytes())
+		data[offset] ^= 0x10
+		if _, _, err := decodeFLAC(data); err == nil {
+			t.Errorf("flipped a bit at byte %d: decoded without error", offset)
+		}
+	}
+}
+
+func TestFLACCompressesSpeechLikeAudio(t *testing.T) {
+	samples := sine(16000, 220, 16000, 8000)
+	var buf bytes.Buffer
+	if err := audio.WriteFLAC(&buf, samples, 16000, 1); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() >= len(samples) {
+		t.Errorf("FLAC is %d bytes, want under half of the %d bytes of PCM", buf.Len(), len(samples)*2)
+	}
+}
+
+func TestFLACRejectsBadFormats(t *testing.T) {
+	var buf bytes.Buffer
+	if err := audio.WriteFLAC(&buf, nil, 16000, 0); err == nil {
+		t.Error("0 channels: want an error")
+	}
+	if err := audio.WriteFLAC(&buf, nil, 16000, 9); err == nil {
+		t.Error("9 channels: want an error")
+	}
+	if err := audio.WriteFLAC(&buf, nil, 0, 1); err == nil {
+		t.Error("rate 0: want an error")
+	}
+}
+
+func TestRecordingStreamMatchesEncode(t *testing.T) {
+	rec := audio.Recording{Samples: sine(9000, 440, 16000, 5000), SampleRate: 16000, Channels: 1}
+	for _, format := range []audio.Format{audio.FLAC, audio.WAV} {
+		encoded, err := rec.Encode(format)
+		if err != nil {
+			t.Fatalf("%s: Encode: %v", format, err)
+		}
+
+		var streamed bytes.Buffer
+		body := rec.Stream(format)
+		if _, err := streamed.ReadFrom(body); err != nil {
+			t.Fatalf("%s: Stream: %v", format, err)
+		}
+		body.Close()
+
+		if !bytes.Equal(encoded, streamed.Bytes()) {
+			t.Errorf("%s: streamed output differs from Encode", format)
+		}
+	}
+}
+
+func constant(n int, v int16) []int16 {
+	samples := make([]int16, n)
+	for i := range samples {
+		samples[i] = v
+	}
+	return samples
+}
+
+func sine(n int, freq, rate, amplitude float64) []int16 {
+	samples := make([]int16, n)
+	for i := range samples {
+		samples[i] = int16(amplitude * math.Sin(2*math.Pi*freq*float64(i)/rate))
+	}
+	return samples
+}
+
+func noise(n int, amplitude int) []int16 {
+	r := rand.New(rand.NewSource(int64(n)))
+	samples := make([]int16, n)
+	for i := range samples {
+		samples[i] = int16(r.Intn(2*amplitude+1) - amplitude)
+	}
+	return samples
+}
+
+func interleave(channels ...[]int16) []int16 {
+	samples := make([]int16, 0, len(channels)*len(channels[0]))
+	for i := range channels[0] {
+		for _, c := range channels {
+			samples = append(samples, c[i])
+		}
+	}
+	return samples
+}
+
+func le(samples []int16) []byte {
+	data := make([]byte, len(samples)*2)
+	for i, s := range samples {
+		binary.LittleEndian.PutUint16(data[i*2:], uint16(s))
+	}
+	return data
+}
+
+// The rest is a minimal FLAC decoder, written from the format
+// specification, for the subset of the format WriteFLAC may produce:
+// 16-bit samples, independent channels and constant, verbatim or fixed
+// subframes.
+
+type streamInfo struct {
+	minBlock, maxBlock int
+	minFrame, maxFrame int
+	sampleRate         int
+	channels           int
+	bits               int
+	total              int
+	md5                [16]byte
+}
+
+func decodeFLAC(data []byte) (streamInfo, []int16, error) {
+	var info streamInfo
+	if !bytes.HasPrefix(data, []byte("fLaC")) {
+		return info, nil, errors.New("missing fLaC marker")
+	}
+	r := &bitReader{data: data, pos: 32}
+
+	for last := false; !last; {
+		last = r.read(1) == 1
+		kind := r.read(7)
+		length := int(r.read(24))
+		if kind != 0 {
+			r.pos += 8 * length
+			continue
+		}
+		if length != 34 {
+			return info, nil, fmt.Errorf("STREAMINFO is %d bytes", length)
+		}
+		info.minBlock = int(r.read(16))
+		info.maxBlock = int(r.read(16))
+		info.minFrame = int(r.read(24))
+		info.maxFrame = int(r.read(24))
+		info.sampleRate = int(r.read(20))
+		info.channels = int(r.read(3)) + 1
+		info.bits = int(r.read(5)) + 1
+		info.total = int(r.read(36))
+		for i := range info.md5 {
+			info.md5[i] = byte(r.read(8))
+		}
+	}
+
+	var samples []int16
+	for number := 0; r.pos < 8*len(data); number++ {
+		frame, err := decodeFrame(r, info, number)
+		if err != nil {
+			return info, nil, fmt.Errorf("frame %d: %w", number, err)
+		}
+		samples = append(samples, frame...)
+	}
+	if r.err != nil {
+		return info, nil, r.err
+	}
+
+	if sum := md5.Sum(le(samples)); sum != info.md5 {
+		return info, nil, errors.New("MD5 of the decoded samples does not match STREAMINFO")
+	}
+	return info, samples, nil
+}
+
+func decodeFrame(r *bitReader, info streamInfo, number int) ([]int16, error) {
+	start := r.pos / 8
+
+	if sync := r.read(14); sync != 0x3FFE {
+		return nil, fmt.Errorf("bad sync code %#x", sync)
+	}
+	if r.read(1) != 0 || r.read(1) != 0 {
+		return nil, errors.New("reserved bit or variable block size set")
+	}
+	sizeCode := r.read(4)
+	rateCode := r.read(4)
+	assignment := int(r.read(4))
+	sampleSize := r.read(3)
+	r.read(1)
+
+	if rateCode != 0 {
+		return nil, fmt.Errorf("sample rate code %d, want it taken from STREAMINFO", rateCode)
+	}
+	if assignment+1 != info.channels {
+		return nil, fmt.Errorf("channel assignment %d for %d channels", assignment, info.channels)
+	}
+	if sampleSize != 4 {
+		return nil, fmt.Errorf("sample size code %d, want 16 bits", sampleSize)
+	}
+
+	if n := readUTF8(r); n != uint64(number) {
+		return nil, fmt.Errorf("frame number %d", n)
+	}
+
+	var size int
+	switch {
+	case sizeCode == 1:
+		size = 192
+	case sizeCode >= 2 && sizeCode <= 5:
+		size = 576 << (sizeCode - 2)
+	case sizeCode == 6:
+		size = int(r.read(8)) + 1
+	case sizeCode == 7:
+		size = int(r.read(16)) + 1
+	case sizeCode >= 8:
+		size = 256 << (sizeCode - 8)
+	default:
+		return nil, errors.New("reserved block size code")
+	}
+
+	if crc := r.read(8); byte(crc) != crc8(r.data[start:r.pos/8-1]) {
+		return nil, errors.New("header CRC-8 mismatch")
+	}
+
+	channels := make([][]int32, info.channels)
+	for c := range channels {
+		var err error
+		if channels[c], err = decodeSubframe(r, size); err != nil {
+			return nil, fmt.Errorf("channel %d: %w", c, err)
+		}
+	}
+
+	r.align()
+	end := r.pos / 8
+	if crc := r.read(16); uint16(crc) != crc16(r.data[start:end]) {
+		return nil, errors.New("frame CRC-16 mismatch")
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	samples := make([]int16, 0, size*info.channels)
+	for i := 0; i < size; i++ {
+		for c := range channels {
+			samples = append(samples, int16(channels[c][i]))
+		}
+	}
+	return samples, nil
+}
+
+func readUTF8(r *bitReader) uint64 {
+	first := r.read(8)
+	if first < 0x80 {
+		return first
+	}
+	n := 0
+	for first&(0x80>>n) != 0 {
+		n++
+	}
+	v := first & (0xFF >> (n + 1))
+	for i := 1; i < n; i++ {
+		v = v<<6 | r.read(8)&0x3F
+	}
+	return v
+}
+
+func decodeSubframe(r *bitReader, size int) ([]int32, error) {
+	if r.read(1) != 0 {
+		return nil, errors.New("subframe padding bit set")
+	}
+	kind := int(r.read(6))
+	if r.read(1) != 0 {
+		return nil, errors.New("wasted bits are not produced by WriteFLAC")
+	}
+
+	x := make([]int32, size)
+	switch {
+	case kind == 0:
+		v := r.signed(16)
+		for i := range x {
+			x[i] = v
+		}
+	case kind == 1:
+		for i := range x {
+			x[i] = r.signed(16)
+		}
+	case kind >= 8 && kind <= 12:
+		order := kind - 8
+		for i := 0; i < order; i++ {
+			x[i] = r.signed(16)
+		}
+		if err := decodeResidual(r, x, order); err != nil {
+			return nil, err
+		}
+		for i := order; i < size; i++ {
+			switch order {
+			case 1:
+				x[i] += x[i-1]
+			case 2:
+				x[i] += 2*x[i-1] - x[i-2]
+			case 3:
+				x[i] += 3*x[i-1] - 3*x[i-2] + x[i-3]
+			case 4:
+				x[i] += 4*x[i-1] - 6*x[i-2] + 4*x[i-3] - x[i-4]
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unexpected subframe type %d", kind)
+	}
+	return x, nil
+}
+
+// decodeResidual reads the residual into x[order:].
+func decodeResidual(r *bitReader, x []int32, order int) error {
+	method := r.read(2)
+	if method > 1 {
+		return fmt.Errorf("reserved residual coding method %d", method)
+	}
+	paramBits, escape := 4, uint64(15)
+	if method == 1 {
+		paramBits, escape = 5, 31
+	}
+
+	partitionOrder := r.read(4)
+	partitions := 1 << partitionOrder
+	if len(x)%partitions != 0 {
+		return fmt.Errorf("block of %d cannot have %d partitions", len(x), partitions)
+	}
+
+	i := order
+	for p := 0; p < partitions; p++ {
+		n := len(x) / partitions
+		if p == 0 {
+			n -= order
+		}
+		k := r.read(uint(paramBits))
+		if k == escape {
+			bits := uint(r.read(5))
+			for j := 0; j < n; j++ {
+				x[i] = r.signed(bits)
+				i++
+			}
+			continue
+		}
+		for j := 0; j < n; j++ {
+			u := r.unary()<<k | r.read(uint(k))
+			x[i] = int32(u>>1) ^ -int32(u&1)
+			i++
+		}
+	}
+	return r.err
+}
+
+type bitReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *bitReader) read(n uint) uint64 {
+	var v uint64
+	for ; n > 0; n-- {
+		if r.pos >= 8*len(r.data) {
+			r.err = errors.New("unexpected end of stream")
+			return 0
+		}
+		bit := r.data[r.pos/8] >> (7 - r.pos%8) & 1
+		v = v<<1 | uint64(bit)
+		r.pos++
+	}
+	return v
+}
+
+func (r *bitReader) signed(n uint) int32 {
+	if n == 0 {
+		return 0
+	}
+	v := r.read(n)
+	return int32(int64(v<<(64-n)) >> (64 - n))
+}
+
+func (r *bitReader) unary() uint64 {
+	var q uint64
+	for r.read(1) == 0 && r.err == nil {
+		q++
+	}
+	return q
+}
+
+func (r *bitReader) align() {
+	r.pos = (r.pos + 7) &^ 7
+}
+
+// crc8 and crc16 are table-driven, unlike the encoder's, so a shared
+// mistake is unlikely.
+var crc8Table, crc16Table = crcTables()
+
+func crcTables() (t8 [256]byte, t16 [256]uint16) {
+	for i := 0; i < 256; i++ {
+		c8 := byte(i)
+		c16 := uint16(i) << 8
+		for j := 0; j < 8; j++ {
+			if c8&0x80 != 0 {
+				c8 = c8<<1 ^ 0x07
+			} else {
+				c8 <<= 1
+			}
+			if c16&0x8000 != 0 {
+				c16 = c16<<1 ^ 0x8005
+			} else {
+				c16 <<= 1
+			}
+		}
+		t8[i], t16[i] = c8, c16
+	}
+	return
+}
+
+func crc8(data []byte) byte {
+	var crc byte
+	for _, b := range data {
+		crc = crc8Table[crc^b]
+	}
+	return crc
+}
+
+func crc16(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		crc = crc<<8 ^ crc16Table[byte(crc>>8)^b]
+	}
+	return crc
+}
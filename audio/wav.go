@@ -0,0 +1,78 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/go-audio/wav"
+)
+
+// wavFormat is the body of the "fmt " chunk, preceded by its size.
+type wavFormat struct {
+	Size          uint32
+	Format        uint16
+	Channels      uint16
+	SampleRate    uint32
+	ByteRate      uint32
+	BlockAlign    uint16
+	BitsPerSample uint16
+}
+
+// WriteWAV writes interleaved samples as 16-bit PCM WAV.
+func WriteWAV(w io.Writer, samples []int16, sampleRate, channels int) error {
+	dataSize := len(samples) * 2
+
+	var header bytes.Buffer
+	header.WriteString("RIFF")
+	binary.Write(&header, binary.LittleEndian, uint32(36+dataSize))
+	header.WriteString("WAVE")
+
+	header.WriteString("fmt ")
+	binary.Write(&header, binary.LittleEndian, wavFormat{
+		Size:          16,
+		Format:        1, // PCM
+		Channels:      uint16(channels),
+		SampleRate:    uint32(sampleRate),
+		ByteRate:      uint32(sampleRate * channels * 2),
+		BlockAlign:    uint16(channels * 2),
+		BitsPerSample: 16,
+	})
+
+	header.WriteString("data")
+	binary.Write(&header, binary.LittleEndian, uint32(dataSize))
+	if _, err := w.Write(header.Bytes()); err != nil {
+		return err
+	}
+
+	return binary.Write(w, binary.LittleEndian, samples)
+}
+
+// DecodeWAV reads a PCM WAV file into interleaved 16-bit samples.
+func DecodeWAV(data []byte) (samples []int16, sampleRate, channels int, err error) {
+	decoder := wav.NewDecoder(bytes.NewReader(data))
+	if !decoder.IsValidFile() {
+		return nil, 0, 0, fmt.Errorf("invalid WAV file")
+	}
+
+	buf, err := decoder.FullPCMBuffer()
+	if err != nil {
+		return nil, 0, 0, fmt.Errorf("WAV decoding error: %w", err)
+	}
+
+	bitDepth := int(decoder.BitDepth)
+	samples = make([]int16, len(buf.Data))
+	for i, sample := range buf.Data {
+		switch {
+		case bitDepth == 8:
+			// 8-bit WAV is unsigned.
+			sample = (sample - 128) << 8
+		case bitDepth > 16:
+			sample >>= bitDepth - 16
+		}
+		samples[i] = int16(sample)
+	}
+
+	return samples, int(decoder.SampleRate), int(decoder.NumChans), nil
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"KevinGo/audio"
+	"KevinGo/conversation"
+	"KevinGo/playback"
+	"KevinGo/skill"
+	"KevinGo/tts"
+	"KevinGo/vad"
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// interruptPatience is how long to wait for the new request after the
+// user pressed Enter to interrupt.
+const interruptPatience = 8 * time.Second
+
+// keypresses delivers Enter presses from a single stdin reader, so
+// push-to-talk and barge-in do not compete for the terminal. Presses that
+// nobody is waiting for are dropped.
+var keypresses = sync.OnceValue(func() <-chan struct{} {
+	keys := make(chan struct{})
+
+	go func() {
+		defer close(keys)
+		reader := bufio.NewReader(os.Stdin)
+		for {
+			if _, err := reader.ReadBytes('\n'); err != nil {
+				return
+			}
+			select {
+			case keys <- struct{}{}:
+			default:
+			}
+		}
+	}()
+
+	return keys
+})
+
+func waitForEnter() {
+	<-keypresses()
+}
+
+// interruption is the start of a request the user made over an answer.
+// seed holds what was captured so far; it is empty when Enter was pressed.
+type interruption struct {
+	mic  *microphone
+	seed []int16
+}
+
+type answer struct {
+	askErr   error
+	speakErr error
+}
+
+// respond prints and speaks the answer to question, which a skill may have
+// answered already. With barge-in enabled
+// the microphone stays open meanwhile: when the user talks over the answer
+// or presses Enter, playback stops and the interruption is returned so
+// the new request is captured from its first word.
+func respond(ctx context.Context, session *conversation.Session, synthesizers tts.Chain, question string, turn skill.Result) (*interruption, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	voice := newSpeaker(ctx, synthesizers)
+
+	var mic *microphone
+	var keys <-chan struct{}
+	if cfg.BargeIn.Enabled {
+		if isTerminal(os.Stdin) {
+			keys = keypresses()
+		}
+		if cfg.Audio.ListenMode != "ptt" {
+			var err error
+			if mic, err = openMicrophone(512); err != nil {
+				log.Printf("⚠️ Cannot listen while speaking: %v", err)
+			}
+		}
+	}
+
+	// printMu keeps streamed text from being printed after the
+	// interruption marker.
+	var printMu sync.Mutex
+	interrupted := false
+
+	done := make(chan answer, 1)
+	go func() {
+		write := func(chunk string) {
+			printMu.Lock()
+			defer printMu.Unlock()
+			if !interrupted {
+				fmt.Print(chunk)
+				voice.Write(chunk)
+			}
+		}
+
+		var err error
+		if turn.Answer != "" {
+			write(turn.Answer)
+			session.Remember(question, turn.Answer)
+		} else {
+			_, err = session.AskStream(ctx, question, turn.Context, write)
+		}
+		if err != nil {
+			voice.Stop()
+			voice.Close()
+			done <- answer{askErr: err}
+			return
+		}
+		done <- answer{speakErr: voice.Close()}
+	}()
+
+	var frames <-chan []int16
+	if mic != nil {
+		frames = mic.frames
+	}
+	listener := newBargeInListener()
+
+	for {
+		var seed []int16
+
+		select {
+		case result := <-done:
+			fmt.Println()
+			if mic != nil {
+				mic.Close()
+			}
+			if result.speakErr != nil {
+				log.Printf("❌ Could not speak the response: %v", result.speakErr)
+			}
+			return nil, result.askErr
+
+		case <-keys:
+
+		case frame, ok := <-frames:
+			if !ok {
+				frames = nil
+				continue
+			}
+			if seed = listener.listen(frame); seed == nil {
+				continue
+			}
+		}
+
+		printMu.Lock()
+		interrupted = true
+		printMu.Unlock()
+
+		// Stopping the generation too leaves the model free for the
+		// next request.
+		voice.Stop()
+		cancel()
+		result := <-done
+		if result.askErr != nil && !errors.Is(result.askErr, context.Canceled) {
+			fmt.Println()
+			if mic != nil {
+				mic.Close()
+			}
+			return nil, result.askErr
+		}
+
+		heard := voice.Spoken()
+		if heard == "" {
+			fmt.Println("\n✋ Interrupted before anything was said.")
+		} else {
+			fmt.Printf("\n✋ Interrupted after: \"%s\"\n", lastWords(heard, 8))
+		}
+		session.Interrupted(heard)
+
+		return &interruption{mic: mic, seed: seed}, nil
+	}
+}
+
+// recordInterruption captures the request that interrupted the last
+// answer, continuing from the audio that was already heard.
+func recordInterruption(in *interruption) (audio.Recording, error) {
+	if cfg.Audio.ListenMode == "ptt" {
+		return recordPushToTalk(true)
+	}
+
+	mic := in.mic
+	if mic == nil {
+		var err error
+		if mic, err = openMicrophone(512); err != nil {
+			return audio.Recording{}, err
+		}
+	}
+	defer mic.Close()
+
+	samples, err := captureUtterance(mic.frames, in.seed, interruptPatience)
+	if err != nil {
+		return audio.Recording{}, err
+	}
+
+	return recording(samples), nil
+}
+
+// bargeInListener decides whether sound picked up during an answer is the
+// user talking. A frame only counts when it is clearly louder than what is
+// being played, which keeps Kira's own voice coming back through the
+// microphone from interrupting her.
+type bargeInListener struct {
+	detector  *vad.Detector
+	preRoll   int
+	minSpeech int
+	captured  []int16
+	speech    int
+}
+
+func newBargeInListener() *bargeInListener {
+	vadCfg := vadConfig()
+	vadCfg.Silence = 200 * time.Millisecond
+	vadCfg.MinSpeech = cfg.BargeIn.MinSpeech
+
+	return &bargeInListener{
+		detector:  vad.New(vadCfg),
+		preRoll:   cfg.Audio.SampleRate * 3 / 10,
+		minSpeech: int(cfg.BargeIn.MinSpeech.Seconds() * float64(cfg.Audio.SampleRate)),
+	}
+}
+
+// listen returns the captured speech, including a short pre-roll, once
+// the user has talked for long enough, and nil until then.
+func (l *bargeInListener) listen(frame []int16) []int16 {
+	l.captured = append(l.captured, frame...)
+
+	gated := frame
+	if vad.RMS(frame) <= playback.OutputLevel()*cfg.BargeIn.EchoRatio {
+		gated = make([]int16, len(frame))
+	}
+	l.detector.Process(gated)
+
+	if !l.detector.InSpeech() {
+		l.speech = 0
+		if len(l.captured) > l.preRoll {
+			l.captured = append(l.captured[:0], l.captured[len(l.captured)-l.preRoll:]...)
+		}
+		return nil
+	}
+
+	l.speech += len(frame)
+	if l.speech < l.minSpeech {
+		return nil
+	}
+
+	return l.captured
+}
+
+func lastWords(text string, n int) string {
+	words := strings.Fields(text)
+	if len(words) <= n {
+		return text
+	}
+	return "..." + strings.Join(words[len(words)-n:], " ")
+}
@@ -0,0 +1,665 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPath is read when no -config flag or KIRA_CONFIG variable is given.
+// A missing default file is not an error.
+const DefaultPath = "kira.yaml"
+
+type Config struct {
+	AssemblyAI AssemblyAIConfig `yaml:"assemblyai"`
+	Weather    WeatherConfig    `yaml:"weather"`
+	Ollama     OllamaConfig     `yaml:"ollama"`
+	Intent     IntentConfig     `yaml:"intent"`
+	Audio      AudioConfig      `yaml:"audio"`
+	VAD        VADConfig        `yaml:"vad"`
+	Wake       WakeConfig       `yaml:"wake"`
+	BargeIn    BargeInConfig    `yaml:"barge_in"`
+	STT        STTConfig        `yaml:"stt"`
+	TTS        TTSConfig        `yaml:"tts"`
+	Text       TextConfig       `yaml:"text"`
+
+	// Command is the diagnostic to run instead of the assistant, from the
+	// first argument: "devices" or "mic-test". It is empty normally.
+	Command string `yaml:"-"`
+}
+
+// TextConfig selects the keyboard chat mode for machines without a
+// microphone.
+type TextConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Speak also plays the answers through the TTS chain.
+	Speak bool `yaml:"speak"`
+}
+
+type AssemblyAIConfig struct {
+	APIKey       string        `yaml:"api_key"`
+	PollInterval time.Duration `yaml:"poll_interval"`
+	PollTimeout  time.Duration `yaml:"poll_timeout"`
+	// StreamingURL overrides the real-time endpoint, for example with
+	// the EU one.
+	StreamingURL string `yaml:"streaming_url"`
+}
+
+type WeatherConfig struct {
+	// Provider is "openweathermap" or "openmeteo". When empty,
+	// OpenWeatherMap is used if APIKey is set and the keyless Open-Meteo
+	// otherwise.
+	Provider string `yaml:"provider"`
+	APIKey   string `yaml:"api_key"`
+	// Home answers questions that name no place, such as "Cluj-Napoca,
+	// Romania". When empty, Kira asks where.
+	Home string `yaml:"home"`
+}
+
+type OllamaConfig struct {
+	URL   string `yaml:"url"`
+	Model string `yaml:"model"`
+	// Timeout bounds requests that return one answer, such as the intent
+	// classifier's. Streamed answers are bounded by ResponseTimeout, the
+	// wait for the first token, which includes loading the model.
+	Timeout         time.Duration `yaml:"timeout"`
+	ConnectTimeout  time.Duration `yaml:"connect_timeout"`
+	ResponseTimeout time.Duration `yaml:"response_timeout"`
+	// Retries is how many times a request is repeated when Ollama cannot
+	// be reached or fails with a server error.
+	Retries int `yaml:"retries"`
+	// Tools lets the model call skills such as the weather or timers
+	// itself. It is turned off at startup for models without tool
+	// support.
+	Tools bool `yaml:"tools"`
+	// MaxToolRounds is how many times in a row the model may call tools
+	// before it has to answer.
+	MaxToolRounds int              `yaml:"max_tool_rounds"`
+	Options       GenerationConfig `yaml:"options"`
+}
+
+// GenerationConfig holds the model parameters sent with every request.
+// Fields left unset keep the model's own defaults.
+type GenerationConfig struct {
+	Temperature *float64 `yaml:"temperature"`
+	TopP        *float64 `yaml:"top_p"`
+	// NumCtx is the context window in tokens; the conversation history is
+	// trimmed to fit it.
+	NumCtx int `yaml:"num_ctx"`
+	// NumPredict caps the length of a reply in tokens.
+	NumPredict int      `yaml:"num_predict"`
+	Stop       []string `yaml:"stop"`
+	// Seed makes replies reproducible, together with a temperature of 0.
+	Seed      *int           `yaml:"seed"`
+	KeepAlive *time.Duration `yaml:"keep_alive"`
+}
+
+// IntentConfig lets the Ollama model classify each question before the
+// keyword matchers do.
+type IntentConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Timeout is how long to wait for the model before falling back to
+	// the keywords.
+	Timeout time.Duration `yaml:"timeout"`
+	// MinConfidence is the lowest confidence that overrides the keywords.
+	MinConfidence float64 `yaml:"min_confidence"`
+}
+
+type AudioConfig struct {
+	SampleRate int    `yaml:"sample_rate"`
+	AssetsDir  string `yaml:"assets_dir"`
+	// ListenMode is "vad" to stop recording on silence or "ptt" to start
+	// and stop with Enter.
+	ListenMode string `yaml:"listen_mode"`
+	// ArchiveDir keeps a WAV copy of every request when set. Recordings
+	// otherwise only exist in memory.
+	ArchiveDir string `yaml:"archive_dir"`
+	// InputDevice and OutputDevice pick a PortAudio device by the index
+	// shown by `kira devices` or by part of its name. Empty uses the
+	// system default.
+	InputDevice  string `yaml:"input_device"`
+	OutputDevice string `yaml:"output_device"`
+}
+
+type VADConfig struct {
+	Threshold    float64       `yaml:"threshold"`
+	Silence      time.Duration `yaml:"silence"`
+	MaxUtterance time.Duration `yaml:"max_utterance"`
+	MinSpeech    time.Duration `yaml:"min_speech"`
+}
+
+// WakeConfig enables hands-free activation. Detection runs with a local
+// whisper.cpp model, so nothing is sent over the network while idle.
+type WakeConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Phrase  string `yaml:"phrase"`
+	// Sensitivity goes from 0 (strict) to 1 (loose).
+	Sensitivity float64 `yaml:"sensitivity"`
+	// WhisperModel defaults to stt.whisper.model; a tiny model is enough.
+	WhisperModel string `yaml:"whisper_model"`
+	// PreRoll is how much recent audio is kept around a wake word clip.
+	PreRoll time.Duration `yaml:"pre_roll"`
+	// Timeout is how long to wait for the request after the wake word.
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// BargeInConfig lets the user interrupt an answer by talking over it or
+// pressing Enter.
+type BargeInConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// MinSpeech is how long the user must talk before playback stops, so
+	// a cough does not cut the answer short.
+	MinSpeech time.Duration `yaml:"min_speech"`
+	// EchoRatio is how much louder than the answer being played the
+	// microphone must be for the sound to count as the user. Raise it
+	// when Kira interrupts herself on loud speakers.
+	EchoRatio float64 `yaml:"echo_ratio"`
+}
+
+type STTConfig struct {
+	Backend string `yaml:"backend"`
+	// UploadFormat is how recordings are encoded for AssemblyAI: flac or
+	// wav, both encoded in-process, or m4a, which needs ffmpeg.
+	UploadFormat string `yaml:"upload_format"`
+	// Streaming sends the audio to AssemblyAI while the user talks and
+	// shows partial transcripts, instead of uploading the recording
+	// afterwards. Wake word and push-to-talk requests are still uploaded.
+	Streaming bool          `yaml:"streaming"`
+	Whisper   WhisperConfig `yaml:"whisper"`
+}
+
+type WhisperConfig struct {
+	Binary   string `yaml:"binary"`
+	Model    string `yaml:"model"`
+	Language string `yaml:"language"`
+}
+
+type TTSConfig struct {
+	// Backends is the fallback order: say, piper, espeak and htgo.
+	Backends []string `yaml:"backends"`
+	// Voice is the macOS say voice.
+	Voice string `yaml:"voice"`
+	// Rate is the speaking rate in words per minute for every backend
+	// that supports it.
+	Rate   int          `yaml:"rate"`
+	Piper  PiperConfig  `yaml:"piper"`
+	Espeak EspeakConfig `yaml:"espeak"`
+	HTGO   HTGOConfig   `yaml:"htgo"`
+}
+
+type PiperConfig struct {
+	Binary string `yaml:"binary"`
+	Model  string `yaml:"model"`
+}
+
+type EspeakConfig struct {
+	Binary string `yaml:"binary"`
+	Voice  string `yaml:"voice"`
+}
+
+type HTGOConfig struct {
+	Language string `yaml:"language"`
+}
+
+func Default() *Config {
+	return &Config{
+		AssemblyAI: AssemblyAIConfig{
+			PollInterval: 500 * time.Millisecond,
+			PollTimeout:  2 * time.Minute,
+		},
+		Ollama: OllamaConfig{
+			URL:             "http://localhost:11434",
+			Model:           "llama3.2",
+			Timeout:         30 * time.Second,
+			ConnectTimeout:  5 * time.Second,
+			ResponseTimeout: 2 * time.Minute,
+			Retries:         2,
+			Tools:           true,
+			MaxToolRounds:   4,
+			Options: GenerationConfig{
+				// Spoken answers are kept to about 50 words by the system
+				// prompt; this stops a model that ignores it.
+				NumPredict: 160,
+			},
+		},
+		Intent: IntentConfig{
+			Timeout:       2 * time.Second,
+			MinConfidence: 0.6,
+		},
+		Audio: AudioConfig{
+			SampleRate: 44100,
+			AssetsDir:  "assets",
+			ListenMode: "vad",
+		},
+		VAD: VADConfig{
+			Threshold:    500,
+			Silence:      time.Second,
+			MaxUtterance: 15 * time.Second,
+			MinSpeech:    300 * time.Millisecond,
+		},
+		Wake: WakeConfig{
+			Phrase:      "hey kira",
+			Sensitivity: 0.5,
+			PreRoll:     5 * time.Second,
+			Timeout:     8 * time.Second,
+		},
+		BargeIn: BargeInConfig{
+			Enabled:   true,
+			MinSpeech: 300 * time.Millisecond,
+			EchoRatio: 0.5,
+		},
+		STT: STTConfig{
+			Backend:      "assemblyai",
+			UploadFormat: "flac",
+			Whisper: WhisperConfig{
+				Language: "auto",
+			},
+		},
+		TTS: TTSConfig{
+			Backends: []string{"say", "piper", "espeak", "htgo"},
+			Voice:    "Samantha",
+			Rate:     180,
+			Espeak: EspeakConfig{
+				Voice: "en-us",
+			},
+			HTGO: HTGOConfig{
+				Language: "en",
+			},
+		},
+	}
+}
+
+// Commands are the diagnostics that can be given as the first argument.
+var Commands = []string{"devices", "mic-test"}
+
+// Load builds the configuration from, in increasing order of precedence,
+// the defaults, the YAML file, KIRA_* environment variables and the
+// command-line flags in args. args may start with one of Commands.
+func Load(args []string) (*Config, error) {
+	cfg := Default()
+
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cfg.Command, args = args[0], args[1:]
+		if !slices.Contains(Commands, cfg.Command) {
+			return nil, fmt.Errorf("unknown command %q (use %s)", cfg.Command, strings.Join(Commands, " or "))
+		}
+	}
+
+	fs := flag.NewFlagSet("kira", flag.ContinueOnError)
+	configPath := fs.String("config", "", "path to the YAML configuration file (default "+DefaultPath+")")
+	ollamaURL := fs.String("ollama-url", "", "Ollama server URL")
+	model := fs.String("model", "", "Ollama model name")
+	sttBackend := fs.String("stt", "", "speech-to-text backend: assemblyai or whisper")
+	whisperModel := fs.String("whisper-model", "", "path to the whisper.cpp model")
+	home := fs.String("home", "", "home location for weather questions, e.g. \"Paris, France\"")
+	voice := fs.String("voice", "", "text-to-speech voice")
+	ttsBackends := fs.String("tts", "", "comma-separated TTS fallback order, e.g. piper,espeak")
+	sampleRate := fs.Int("sample-rate", 0, "microphone sample rate in Hz")
+	assetsDir := fs.String("assets", "", "folder for temporary audio files")
+	archiveDir := fs.String("archive", "", "folder to keep a copy of every recorded request in")
+	text := fs.Bool("text", false, "type questions instead of speaking them")
+	speak := fs.Bool("speak", false, "in text mode, also speak the answers")
+	wake := fs.Bool("wake", false, "wait for the wake phrase before each request")
+	tools := fs.Bool("tools", false, "let the model call skills as tools")
+	classify := fs.Bool("classify", false, "let the Ollama model classify each question")
+	bargeIn := fs.Bool("barge-in", false, "listen while speaking so answers can be interrupted")
+	listenMode := fs.String("listen", "", "listen mode: vad (stop on silence) or ptt (push-to-talk)")
+	stream := fs.Bool("stream", false, "transcribe with AssemblyAI while the user talks")
+	inputDevice := fs.String("input-device", "", "microphone to record from, by index or name (see kira devices)")
+	outputDevice := fs.String("output-device", "", "device to play answers on, by index or name (see kira devices)")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: kira [%s] [flags]\n", strings.Join(Commands, " | "))
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	path, required := *configPath, true
+	if path == "" {
+		path = os.Getenv("KIRA_CONFIG")
+	}
+	if path == "" {
+		path, required = DefaultPath, false
+	}
+
+	if err := cfg.loadFile(path, required); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.loadEnv(); err != nil {
+		return nil, err
+	}
+
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "ollama-url":
+			cfg.Ollama.URL = *ollamaURL
+		case "model":
+			cfg.Ollama.Model = *model
+		case "stt":
+			cfg.STT.Backend = *sttBackend
+		case "whisper-model":
+			cfg.STT.Whisper.Model = *whisperModel
+		case "home":
+			cfg.Weather.Home = *home
+		case "voice":
+			cfg.TTS.Voice = *voice
+		case "tts":
+			cfg.TTS.Backends = splitList(*ttsBackends)
+		case "sample-rate":
+			cfg.Audio.SampleRate = *sampleRate
+		case "assets":
+			cfg.Audio.AssetsDir = *assetsDir
+		case "archive":
+			cfg.Audio.ArchiveDir = *archiveDir
+		case "input-device":
+			cfg.Audio.InputDevice = *inputDevice
+		case "output-device":
+			cfg.Audio.OutputDevice = *outputDevice
+		case "listen":
+			cfg.Audio.ListenMode = *listenMode
+		case "stream":
+			cfg.STT.Streaming = *stream
+		case "wake":
+			cfg.Wake.Enabled = *wake
+		case "tools":
+			cfg.Ollama.Tools = *tools
+		case "classify":
+			cfg.Intent.Enabled = *classify
+		case "barge-in":
+			cfg.BargeIn.Enabled = *bargeIn
+		case "text":
+			cfg.Text.Enabled = *text
+		case "speak":
+			cfg.Text.Speak = *speak
+		}
+	})
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string, required bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !required {
+			return nil
+		}
+		return fmt.Errorf("error reading config file: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return fmt.Errorf("error parsing config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (c *Config) loadEnv() error {
+	stringVars := map[string]*string{
+		"KIRA_ASSEMBLYAI_API_KEY": &c.AssemblyAI.APIKey,
+		"KIRA_STREAMING_URL":      &c.AssemblyAI.StreamingURL,
+		"KIRA_WEATHER_API_KEY":    &c.Weather.APIKey,
+		"KIRA_WEATHER_PROVIDER":   &c.Weather.Provider,
+		"KIRA_WEATHER_HOME":       &c.Weather.Home,
+		"KIRA_OLLAMA_URL":         &c.Ollama.URL,
+		"KIRA_OLLAMA_MODEL":       &c.Ollama.Model,
+		"KIRA_ASSETS_DIR":         &c.Audio.AssetsDir,
+		"KIRA_ARCHIVE_DIR":        &c.Audio.ArchiveDir,
+		"KIRA_LISTEN_MODE":        &c.Audio.ListenMode,
+		"KIRA_INPUT_DEVICE":       &c.Audio.InputDevice,
+		"KIRA_OUTPUT_DEVICE":      &c.Audio.OutputDevice,
+		"KIRA_STT":                &c.STT.Backend,
+		"KIRA_UPLOAD_FORMAT":      &c.STT.UploadFormat,
+		"KIRA_WHISPER_BIN":        &c.STT.Whisper.Binary,
+		"KIRA_WHISPER_MODEL":      &c.STT.Whisper.Model,
+		"KIRA_WHISPER_LANGUAGE":   &c.STT.Whisper.Language,
+		"KIRA_TTS_VOICE":          &c.TTS.Voice,
+		"KIRA_PIPER_MODEL":        &c.TTS.Piper.Model,
+		"KIRA_ESPEAK_VOICE":       &c.TTS.Espeak.Voice,
+		"KIRA_WAKE_PHRASE":        &c.Wake.Phrase,
+		"KIRA_WAKE_WHISPER_MODEL": &c.Wake.WhisperModel,
+	}
+	for name, field := range stringVars {
+		if value, ok := os.LookupEnv(name); ok {
+			*field = value
+		}
+	}
+
+	intVars := map[string]*int{
+		"KIRA_SAMPLE_RATE": &c.Audio.SampleRate,
+		"KIRA_TTS_RATE":    &c.TTS.Rate,
+	}
+	for name, field := range intVars {
+		if value, ok := os.LookupEnv(name); ok {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", name, err)
+			}
+			*field = n
+		}
+	}
+
+	boolVars := map[string]*bool{
+		"KIRA_TEXT":          &c.Text.Enabled,
+		"KIRA_WAKE":          &c.Wake.Enabled,
+		"KIRA_OLLAMA_TOOLS":  &c.Ollama.Tools,
+		"KIRA_INTENT":        &c.Intent.Enabled,
+		"KIRA_BARGE_IN":      &c.BargeIn.Enabled,
+		"KIRA_STT_STREAMING": &c.STT.Streaming,
+	}
+	for name, field := range boolVars {
+		if value, ok := os.LookupEnv(name); ok {
+			enabled, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", name, err)
+			}
+			*field = enabled
+		}
+	}
+
+	if value, ok := os.LookupEnv("KIRA_TTS_BACKENDS"); ok {
+		c.TTS.Backends = splitList(value)
+	}
+
+	if value, ok := os.LookupEnv("KIRA_WAKE_SENSITIVITY"); ok {
+		sensitivity, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid KIRA_WAKE_SENSITIVITY: %w", err)
+		}
+		c.Wake.Sensitivity = sensitivity
+	}
+
+	return nil
+}
+
+// Validate reports settings that would make Kira fail later in the turn.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if u, err := url.Parse(c.Ollama.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, fmt.Errorf("ollama.url must be an http(s) URL, got %q", c.Ollama.URL))
+	}
+	if c.Ollama.Model == "" {
+		errs = append(errs, errors.New("ollama.model is required"))
+	}
+	if c.Ollama.Timeout <= 0 || c.Ollama.ConnectTimeout <= 0 || c.Ollama.ResponseTimeout <= 0 {
+		errs = append(errs, errors.New("ollama.timeout, connect_timeout and response_timeout must be positive"))
+	}
+	if c.Ollama.Retries < 0 {
+		errs = append(errs, fmt.Errorf("ollama.retries must not be negative, got %d", c.Ollama.Retries))
+	}
+	if c.Ollama.Tools && c.Ollama.MaxToolRounds <= 0 {
+		errs = append(errs, errors.New("ollama.max_tool_rounds must be positive"))
+	}
+	if o := c.Ollama.Options; o.NumCtx < 0 || o.NumPredict < 0 {
+		errs = append(errs, errors.New("ollama.options.num_ctx and num_predict must not be negative"))
+	}
+	if o := c.Ollama.Options; o.Temperature != nil && *o.Temperature < 0 {
+		errs = append(errs, fmt.Errorf("ollama.options.temperature must not be negative, got %v", *o.Temperature))
+	}
+	if o := c.Ollama.Options; o.TopP != nil && (*o.TopP <= 0 || *o.TopP > 1) {
+		errs = append(errs, fmt.Errorf("ollama.options.top_p must be between 0 and 1, got %v", *o.TopP))
+	}
+
+	if c.Intent.Enabled {
+		if c.Intent.Timeout <= 0 {
+			errs = append(errs, errors.New("intent.timeout must be positive"))
+		}
+		if c.Intent.MinConfidence < 0 || c.Intent.MinConfidence > 1 {
+			errs = append(errs, fmt.Errorf("intent.min_confidence must be between 0 and 1, got %v", c.Intent.MinConfidence))
+		}
+	}
+
+	switch c.Weather.Provider {
+	case "", "openweathermap", "openmeteo":
+	default:
+		errs = append(errs, fmt.Errorf("unknown weather provider %q (use openweathermap or openmeteo)", c.Weather.Provider))
+	}
+
+	if c.Audio.SampleRate < 8000 || c.Audio.SampleRate > 192000 {
+		errs = append(errs, fmt.Errorf("audio.sample_rate must be between 8000 and 192000, got %d", c.Audio.SampleRate))
+	}
+	if c.Audio.AssetsDir == "" {
+		errs = append(errs, errors.New("audio.assets_dir is required"))
+	}
+
+	// Text mode never records and the diagnostics never transcribe, so the
+	// microphone and speech-to-text settings do not need to be usable.
+	if !c.Text.Enabled && c.Command == "" {
+		errs = append(errs, c.validateVoiceInput()...)
+	}
+
+	if len(c.TTS.Backends) == 0 {
+		errs = append(errs, errors.New("tts.backends must list at least one backend"))
+	}
+	for _, backend := range c.TTS.Backends {
+		switch backend {
+		case "say", "piper", "espeak", "htgo":
+		default:
+			errs = append(errs, fmt.Errorf("unknown TTS backend %q (use say, piper, espeak or htgo)", backend))
+		}
+	}
+	if c.TTS.Rate <= 0 {
+		errs = append(errs, fmt.Errorf("tts.rate must be positive, got %d", c.TTS.Rate))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+
+	return nil
+}
+
+func (c *Config) validateVoiceInput() []error {
+	var errs []error
+
+	if c.Audio.ListenMode != "vad" && c.Audio.ListenMode != "ptt" {
+		errs = append(errs, fmt.Errorf("audio.listen_mode must be vad or ptt, got %q", c.Audio.ListenMode))
+	}
+	if c.VAD.Threshold <= 0 {
+		errs = append(errs, fmt.Errorf("vad.threshold must be positive, got %v", c.VAD.Threshold))
+	}
+	if c.VAD.Silence <= 0 || c.VAD.MaxUtterance <= 0 {
+		errs = append(errs, errors.New("vad.silence and vad.max_utterance must be positive"))
+	}
+	if c.VAD.MinSpeech < 0 || c.VAD.MinSpeech >= c.VAD.MaxUtterance {
+		errs = append(errs, errors.New("vad.min_speech must be shorter than vad.max_utterance"))
+	}
+
+	if c.Wake.Enabled {
+		if c.Wake.Phrase == "" {
+			errs = append(errs, errors.New("wake.phrase is required when wake word mode is enabled"))
+		}
+		if c.Wake.Sensitivity < 0 || c.Wake.Sensitivity > 1 {
+			errs = append(errs, fmt.Errorf("wake.sensitivity must be between 0 and 1, got %v", c.Wake.Sensitivity))
+		}
+		if c.Wake.WhisperModel == "" && c.STT.Whisper.Model == "" {
+			errs = append(errs, errors.New("wake word mode needs wake.whisper_model or stt.whisper.model for local detection"))
+		}
+		if c.Wake.PreRoll < 3*time.Second {
+			errs = append(errs, errors.New("wake.pre_roll must be at least 3s to hold a full wake word clip"))
+		}
+	}
+
+	if c.BargeIn.Enabled {
+		if c.BargeIn.MinSpeech <= 0 {
+			errs = append(errs, errors.New("barge_in.min_speech must be positive"))
+		}
+		if c.BargeIn.EchoRatio < 0 {
+			errs = append(errs, fmt.Errorf("barge_in.echo_ratio must not be negative, got %v", c.BargeIn.EchoRatio))
+		}
+	}
+
+	switch c.STT.Backend {
+	case "assemblyai":
+		if c.AssemblyAI.APIKey == "" {
+			errs = append(errs, errors.New("assemblyai.api_key is required for the assemblyai backend (or set KIRA_ASSEMBLYAI_API_KEY)"))
+		}
+		if c.AssemblyAI.PollInterval <= 0 {
+			errs = append(errs, errors.New("assemblyai.poll_interval must be positive"))
+		}
+		if c.AssemblyAI.StreamingURL != "" {
+			if u, err := url.Parse(c.AssemblyAI.StreamingURL); err != nil || (u.Scheme != "ws" && u.Scheme != "wss") || u.Host == "" {
+				errs = append(errs, fmt.Errorf("assemblyai.streaming_url must be a ws(s) URL, got %q", c.AssemblyAI.StreamingURL))
+			}
+		}
+		switch c.STT.UploadFormat {
+		case "flac", "wav", "m4a":
+		default:
+			errs = append(errs, fmt.Errorf("stt.upload_format must be flac, wav or m4a, got %q", c.STT.UploadFormat))
+		}
+	case "whisper":
+		if c.STT.Whisper.Model == "" {
+			errs = append(errs, errors.New("stt.whisper.model is required for the whisper backend"))
+		}
+		if c.STT.Streaming {
+			errs = append(errs, errors.New("stt.streaming needs the assemblyai backend"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("stt.backend must be assemblyai or whisper, got %q", c.STT.Backend))
+	}
+
+	return errs
+}
+
+// Warnings lists optional settings that are missing. Kira still starts, but
+// the related feature will not work.
+func (c *Config) Warnings() []string {
+	var warnings []string
+
+	if c.Weather.Provider == "openweathermap" && c.Weather.APIKey == "" {
+		warnings = append(warnings, "weather.api_key is not set, weather questions will fail")
+	}
+	if c.STT.Backend == "assemblyai" && c.STT.UploadFormat == "m4a" {
+		if _, err := exec.LookPath("ffmpeg"); err != nil {
+			warnings = append(warnings, "ffmpeg is not installed, stt.upload_format m4a will fail (use flac)")
+		}
+	}
+
+	return warnings
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLoadEnvBools(t *testing.T) {
+	t.Setenv("KIRA_TEXT", "true")
+	t.Setenv("KIRA_BARGE_IN", "1")
+	t.Setenv("KIRA_STT_STREAMING", "false")
+
+	c := Config{}
+	c.STT.Streaming = true
+	if err := c.loadEnv(); err != nil {
+		t.Fatalf("loadEnv: %v", err)
+	}
+	if !c.Text.Enabled || !c.BargeIn.Enabled || c.STT.Streaming {
+		t.Errorf("text=%v barge-in=%v streaming=%v, want true true false", c.Text.Enabled, c.BargeIn.Enabled, c.STT.Streaming)
+	}
+}
+
+func TestLoadEnvInvalidBool(t *testing.T) {
+	t.Setenv("KIRA_WAKE", "sometimes")
+
+	c := Config{}
+	if err := c.loadEnv(); err == nil || !strings.Contains(err.Error(), "KIRA_WAKE") {
+		t.Errorf("err = %v, want one naming KIRA_WAKE", err)
+	}
+}
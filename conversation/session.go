@@ -0,0 +1,188 @@
+package conversation
+
+import (
+	"KevinGo/ollama"
+	"context"
+	"errors"
+	"fmt"
+)
+
+// DefaultMaxTokens matches the context window Ollama allocates when no
+// num_ctx option is sent. Set MaxTokens to num_ctx when it is.
+const DefaultMaxTokens = 2048
+
+// replyReserve is the part of the context window kept free for the answer.
+const replyReserve = 256
+
+// DefaultMaxToolRounds is how many times in a row the model may call tools
+// before it has to answer with what it has.
+const DefaultMaxToolRounds = 4
+
+// Toolbox runs the tools the model calls. skill.Registry is one.
+type Toolbox interface {
+	Tools() []ollama.Tool
+	Call(ctx context.Context, call ollama.ToolCall) (string, error)
+}
+
+// Session keeps the role-tagged history of a conversation with Kira and
+// sends it to Ollama's chat endpoint on every turn.
+type Session struct {
+	SystemPrompt string
+	MaxTokens    int
+	// Tools, when set, are offered to the model on every turn.
+	Tools         Toolbox
+	MaxToolRounds int
+	// Client is the Ollama server to talk to; nil means
+	// ollama.DefaultClient.
+	Client *ollama.Client
+	// Options override the client's options for this conversation, for
+	// example a fixed seed in tests.
+	Options ollama.Options
+
+	history []ollama.ChatMessage
+}
+
+func NewSession(systemPrompt string) *Session {
+	return &Session{
+		SystemPrompt: systemPrompt,
+		MaxTokens:    DefaultMaxTokens,
+	}
+}
+
+// AskStream sends the question together with the previous turns and streams
+// the reply through onChunk. turnContext is extra system information (such
+// as weather data) that only applies to this turn and is not kept in the
+// history. The question and the reply are remembered when the call
+// succeeds, and also when ctx is cancelled, as when the user interrupts,
+// so Interrupted can replace the partial reply. Tool calls and their
+// results are not remembered.
+func (s *Session) AskStream(ctx context.Context, question, turnContext string, onChunk func(string)) (string, error) {
+	userMessage := ollama.ChatMessage{Role: "user", Content: question}
+
+	s.trim(estimateTokens(turnContext) + estimateTokens(question))
+
+	messages := make([]ollama.ChatMessage, 0, len(s.history)+3)
+	messages = append(messages, ollama.ChatMessage{Role: "system", Content: s.SystemPrompt})
+	messages = append(messages, s.history...)
+	if turnContext != "" {
+		messages = append(messages, ollama.ChatMessage{Role: "system", Content: turnContext})
+	}
+	messages = append(messages, userMessage)
+
+	response, err := s.chat(ctx, messages, onChunk)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		return "", err
+	}
+
+	s.history = append(s.history, userMessage, ollama.ChatMessage{Role: "assistant", Content: response})
+
+	return response, err
+}
+
+// chat runs the tools the model calls and asks again with their results,
+// until it answers. After MaxToolRounds rounds of calls the tools are
+// withheld, so a model that keeps calling them still has to answer.
+func (s *Session) chat(ctx context.Context, messages []ollama.ChatMessage, onChunk func(string)) (string, error) {
+	client := s.Client
+	if client == nil {
+		client = ollama.DefaultClient
+	}
+
+	maxRounds := s.MaxToolRounds
+	if maxRounds <= 0 {
+		maxRounds = DefaultMaxToolRounds
+	}
+
+	for round := 0; ; round++ {
+		var tools []ollama.Tool
+		if s.Tools != nil && round < maxRounds {
+			tools = s.Tools.Tools()
+		}
+
+		reply, err := client.ChatStream(ctx, messages, tools, onChunk, s.Options)
+		if err != nil {
+			return reply.Content, err
+		}
+		if len(reply.ToolCalls) == 0 || tools == nil {
+			return reply.Content, nil
+		}
+
+		messages = append(messages, reply)
+		for _, call := range reply.ToolCalls {
+			result, err := s.Tools.Call(ctx, call)
+			if err != nil {
+				result = fmt.Sprintf("error: %v", err)
+			}
+			messages = append(messages, ollama.ToolResult(call.Function.Name, result))
+		}
+	}
+}
+
+// Remember records an exchange that was answered without the LLM, such as
+// telling the time, so later questions can refer to it.
+func (s *Session) Remember(question, reply string) {
+	s.trim(estimateTokens(question) + estimateTokens(reply))
+	s.history = append(s.history,
+		ollama.ChatMessage{Role: "user", Content: question},
+		ollama.ChatMessage{Role: "assistant", Content: reply})
+}
+
+// Interrupted replaces the last reply with the part the user heard before
+// cutting Kira off, so the next answer does not build on the rest.
+func (s *Session) Interrupted(heard string) {
+	last := len(s.history) - 1
+	if last < 0 || s.history[last].Role != "assistant" {
+		return
+	}
+
+	if heard == "" {
+		heard = "(interrupted before saying anything)"
+	} else {
+		heard += "... (interrupted by the user)"
+	}
+	s.history[last].Content = heard
+}
+
+func (s *Session) History() []ollama.ChatMessage {
+	return append([]ollama.ChatMessage(nil), s.history...)
+}
+
+func (s *Session) Reset() {
+	s.history = nil
+}
+
+// trim drops the oldest exchanges until the system prompt, the remaining
+// history and the upcoming turn fit in the context window with room left
+// for the reply.
+func (s *Session) trim(upcoming int) {
+	maxTokens := s.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = DefaultMaxTokens
+	}
+	budget := maxTokens - replyReserve - estimateTokens(s.SystemPrompt) - upcoming
+
+	total := 0
+	for _, message := range s.history {
+		total += estimateTokens(message.Content)
+	}
+
+	for total > budget && len(s.history) > 0 {
+		drop := 2
+		if len(s.history) < drop {
+			drop = len(s.history)
+		}
+		for _, message := range s.history[:drop] {
+			total -= estimateTokens(message.Content)
+		}
+		s.history = s.history[drop:]
+	}
+}
+
+// estimateTokens uses the common approximation of four characters per
+// token, plus a small overhead for the role markers of each message.
+func estimateTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+	return len(text)/4 + 4
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"KevinGo/playback"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gordonklaus/portaudio"
+)
+
+// commonRates are the sample rates `kira devices` checks on every device.
+var commonRates = []float64{8000, 16000, 22050, 32000, 44100, 48000, 96000}
+
+// runDevices lists the PortAudio host APIs with their devices, the rates
+// each one supports and the ones Kira will use.
+func runDevices(w io.Writer) error {
+	if err := portaudio.Initialize(); err != nil {
+		return fmt.Errorf("PortAudio error: %w", err)
+	}
+	defer portaudio.Terminate()
+
+	apis, err := portaudio.HostApis()
+	if err != nil {
+		return fmt.Errorf("PortAudio error: %w", err)
+	}
+	defaultAPI, _ := portaudio.DefaultHostApi()
+	defaultIn, _ := portaudio.DefaultInputDevice()
+	defaultOut, _ := portaudio.DefaultOutputDevice()
+	in, inErr := findDevice(cfg.Audio.InputDevice, true)
+	out, outErr := findDevice(cfg.Audio.OutputDevice, false)
+
+	for _, api := range apis {
+		if api == defaultAPI {
+			fmt.Fprintf(w, "\n🎛️ %s (default)\n", api.Name)
+		} else {
+			fmt.Fprintf(w, "\n🎛️ %s\n", api.Name)
+		}
+
+		for _, dev := range api.Devices {
+			var roles []string
+			if dev == defaultIn {
+				roles = append(roles, "default input")
+			}
+			if dev == defaultOut {
+				roles = append(roles, "default output")
+			}
+			if dev == in {
+				roles = append(roles, "Kira's microphone")
+			}
+			if dev == out {
+				roles = append(roles, "Kira's speaker")
+			}
+
+			fmt.Fprintf(w, "  [%d] %s", dev.Index, dev.Name)
+			if len(roles) > 0 {
+				fmt.Fprintf(w, "  ← %s", strings.Join(roles, ", "))
+			}
+			fmt.Fprintln(w)
+
+			if dev.MaxInputChannels > 0 {
+				fmt.Fprintf(w, "      🎤 %d in, %s\n", dev.MaxInputChannels, formatRates(supportedRates(dev, true)))
+			}
+			if dev.MaxOutputChannels > 0 {
+				fmt.Fprintf(w, "      🔊 %d out, %s\n", dev.MaxOutputChannels, formatRates(supportedRates(dev, false)))
+			}
+		}
+	}
+
+	fmt.Fprintln(w)
+	if inErr != nil {
+		fmt.Fprintf(w, "⚠️ audio.input_device: %v\n", inErr)
+	}
+	if outErr != nil {
+		fmt.Fprintf(w, "⚠️ audio.output_device: %v\n", outErr)
+	}
+	fmt.Fprintln(w, "Choose devices with audio.input_device and audio.output_device, by index or name.")
+	return nil
+}
+
+// supportedRates returns the common rates the device can record mono or
+// play stereo 16-bit audio at.
+func supportedRates(dev *portaudio.DeviceInfo, input bool) []float64 {
+	var p portaudio.StreamParameters
+	if input {
+		p.Input = portaudio.StreamDeviceParameters{Device: dev, Channels: 1, Latency: dev.DefaultHighInputLatency}
+	} else {
+		p.Output = portaudio.StreamDeviceParameters{Device: dev, Channels: min(dev.MaxOutputChannels, 2), Latency: dev.DefaultHighOutputLatency}
+	}
+
+	var rates []float64
+	for _, rate := range commonRates {
+		p.SampleRate = rate
+		if portaudio.IsFormatSupported(p, make([]int16, 1)) == nil {
+			rates = append(rates, rate)
+		}
+	}
+	return rates
+}
+
+func formatRates(rates []float64) string {
+	if len(rates) == 0 {
+		return "no common sample rate"
+	}
+
+	names := make([]string, len(rates))
+	for i, rate := range rates {
+		names[i] = strconv.FormatFloat(rate, 'f', -1, 64)
+	}
+	return strings.Join(names, ", ") + " Hz"
+}
+
+// findDevice resolves a device setting: the index shown by `kira devices`,
+// or part of the device name, ignoring case. An empty setting is the
+// system default. PortAudio must be initialized.
+func findDevice(setting string, input bool) (*portaudio.DeviceInfo, error) {
+	kind, usable := "output", func(dev *portaudio.DeviceInfo) bool { return dev.MaxOutputChannels > 0 }
+	if input {
+		kind, usable = "input", func(dev *portaudio.DeviceInfo) bool { return dev.MaxInputChannels > 0 }
+	}
+
+	if setting == "" {
+		if input {
+			return portaudio.DefaultInputDevice()
+		}
+		return portaudio.DefaultOutputDevice()
+	}
+
+	devices, err := portaudio.Devices()
+	if err != nil {
+		return nil, fmt.Errorf("PortAudio error: %w", err)
+	}
+
+	if index, err := strconv.Atoi(setting); err == nil {
+		for _, dev := range devices {
+			if dev.Index != index {
+				continue
+			}
+			if !usable(dev) {
+				return nil, fmt.Errorf("device %d (%s) has no %s channels", index, dev.Name, kind)
+			}
+			return dev, nil
+		}
+		return nil, fmt.Errorf("no audio device with index %d (see kira devices)", index)
+	}
+
+	var matches []*portaudio.DeviceInfo
+	for _, dev := range devices {
+		if !usable(dev) {
+			continue
+		}
+		if strings.EqualFold(dev.Name, setting) {
+			return dev, nil
+		}
+		if strings.Contains(strings.ToLower(dev.Name), strings.ToLower(setting)) {
+			matches = append(matches, dev)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no %s device matches %q (see kira devices)", kind, setting)
+	case 1:
+		return matches[0], nil
+	}
+
+	names := make([]string, len(matches))
+	for i, dev := range matches {
+		names[i] = fmt.Sprintf("[%d] %s", dev.Index, dev.Name)
+	}
+	return nil, fmt.Errorf("%q matches several %s devices, use an index: %s", setting, kind, strings.Join(names, ", "))
+}
+
+// selectDevices checks the configured devices before the first turn and
+// sends playback to the chosen output. PortAudio must be initialized.
+func selectDevices(w io.Writer, input bool) error {
+	if input {
+		dev, err := findDevice(cfg.Audio.InputDevice, true)
+		if err != nil {
+			return fmt.Errorf("audio.input_device: %w", err)
+		}
+		if cfg.Audio.InputDevice != "" {
+			fmt.Fprintf(w, "🎤 Microphone: %s\n", dev.Name)
+		}
+	}
+
+	if cfg.Audio.OutputDevice == "" {
+		return nil
+	}
+	dev, err := findDevice(cfg.Audio.OutputDevice, false)
+	if err != nil {
+		return fmt.Errorf("audio.output_device: %w", err)
+	}
+	playback.OutputDevice = dev
+	fmt.Fprintf(w, "🔊 Speaker: %s\n", dev.Name)
+	return nil
+}
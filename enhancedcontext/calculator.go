@@ -0,0 +1,253 @@
+package enhancedcontext
+
+import (
+	"KevinGo/ollama"
+	"KevinGo/skill"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// spokenOperators turn arithmetic as it is said into symbols, longest
+// phrases first.
+var spokenOperators = strings.NewReplacer(
+	"to the power of", "^", "multiplied by", "*", "divided by", "/",
+	"percent of", "% *", "squared", "^2", "cubed", "^3",
+	"times", "*", "plus", "+", "minus", "-", "over", "/",
+	"×", "*", "÷", "/", ",", "",
+)
+
+var (
+	arithmetic = regexp.MustCompile(`[-+*/^%().\d\s]*\d[-+*/^%().\d\s]*`)
+	spokenX    = regexp.MustCompile(`(\d)\s*x\s*(\d)`)
+)
+
+// calculatorSkill works out arithmetic exactly, which small models get
+// wrong.
+type calculatorSkill struct {
+	matcher skill.Matcher
+}
+
+func newCalculatorSkill() *calculatorSkill {
+	return &calculatorSkill{
+		matcher: skill.Intent("calculator", skill.Any(
+			// A dash needs spaces to be a minus, so "1989-1991" is a range.
+			skill.Pattern(1, `(?i)\d\s*([+*/^×÷x]|\s-\s|plus|minus|times|multiplied\s+by|divided\s+by|over|to\s+the\s+power\s+of|percent\s+of)\s*\(?\s*-?\d`),
+			skill.Pattern(1, `(?i)\b\d+\s+(squared|cubed)\b`),
+			skill.Pattern(0.6, `(?i)\b(calculate|compute)\b`),
+		)),
+	}
+}
+
+func (c *calculatorSkill) Name() string { return "calculator" }
+
+func (c *calculatorSkill) Description() string {
+	return "arithmetic, such as 12 times 7 or 15 percent of 80"
+}
+
+func (c *calculatorSkill) Match(ctx context.Context, query string) float64 {
+	return c.matcher.Score(ctx, query)
+}
+
+// Answer leaves questions it cannot read as arithmetic to the LLM.
+func (c *calculatorSkill) Answer(_ context.Context, query string) (string, error) {
+	result, err := calculate(query)
+	if err != nil {
+		return "", nil
+	}
+	return fmt.Sprintf("That's %s.", result), nil
+}
+
+func (c *calculatorSkill) Context(_ context.Context, query string) (string, error) {
+	return `
+CALCULATOR CONTEXT:
+The question looks like arithmetic that could not be worked out automatically.
+
+INSTRUCTIONS:
+- Work it out step by step and double-check the result
+- Always respond in English as instructed in your main context`, nil
+}
+
+func (c *calculatorSkill) Definitions() []ollama.Tool {
+	return []ollama.Tool{
+		ollama.NewTool("calculate", "Evaluate an arithmetic expression exactly. Supports + - * / ^ % and parentheses.",
+			map[string]ollama.ToolProperty{
+				"expression": {Type: "string", Description: `The expression, for example "(12 + 3) * 7" or "15 % * 80"`},
+			}, "expression"),
+	}
+}
+
+func (c *calculatorSkill) Call(_ context.Context, call ollama.ToolCall) (string, error) {
+	var args struct {
+		Expression string `json:"expression"`
+	}
+	if err := call.DecodeArguments(&args); err != nil {
+		return "", err
+	}
+	return calculate(args.Expression)
+}
+
+// calculate finds the arithmetic in text, spoken or written, and
+// evaluates it.
+func calculate(text string) (string, error) {
+	text = spokenOperators.Replace(strings.ToLower(text))
+	text = spokenX.ReplaceAllString(text, "$1 * $2")
+
+	expression := ""
+	for _, candidate := range arithmetic.FindAllString(text, -1) {
+		if len(strings.TrimSpace(candidate)) > len(expression) {
+			expression = strings.TrimSpace(candidate)
+		}
+	}
+	expression = strings.Join(strings.Fields(expression), " ")
+	if !strings.ContainsAny(expression, "+-*/^%") {
+		return "", errors.New("no arithmetic found")
+	}
+
+	p := &parser{input: expression}
+	value, err := p.expression()
+	if err != nil {
+		return "", err
+	}
+	if p.skipSpaces(); p.pos < len(p.input) {
+		return "", fmt.Errorf("unexpected %q in %q", p.input[p.pos:], expression)
+	}
+	if math.IsInf(value, 0) || math.IsNaN(value) {
+		return "", errors.New("the result is not a number")
+	}
+
+	return strconv.FormatFloat(math.Round(value*1e6)/1e6, 'f', -1, 64), nil
+}
+
+// parser evaluates + - * / % ^ and parentheses with the usual precedence.
+// A % after a number makes it a percentage.
+type parser struct {
+	input string
+	pos   int
+}
+
+func (p *parser) skipSpaces() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *parser) peek() byte {
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return p.input[p.pos]
+	}
+	return 0
+}
+
+func (p *parser) expression() (float64, error) {
+	left, err := p.term()
+	for err == nil {
+		op := p.peek()
+		if op != '+' && op != '-' {
+			return left, nil
+		}
+		p.pos++
+
+		var right float64
+		if right, err = p.term(); op == '+' {
+			left += right
+		} else {
+			left -= right
+		}
+	}
+	return 0, err
+}
+
+func (p *parser) term() (float64, error) {
+	left, err := p.unary()
+	for err == nil {
+		op := p.peek()
+		if op != '*' && op != '/' {
+			return left, nil
+		}
+		p.pos++
+
+		var right float64
+		if right, err = p.unary(); err != nil {
+			break
+		}
+		if op == '*' {
+			left *= right
+		} else if right == 0 {
+			return 0, errors.New("division by zero")
+		} else {
+			left /= right
+		}
+	}
+	return 0, err
+}
+
+// unary applies a sign to a power, so -2^2 is -4.
+func (p *parser) unary() (float64, error) {
+	switch p.peek() {
+	case '-':
+		p.pos++
+		value, err := p.unary()
+		return -value, err
+	case '+':
+		p.pos++
+		return p.unary()
+	}
+	return p.power()
+}
+
+// power is right-associative, and its exponent may have a sign: 2^-1.
+func (p *parser) power() (float64, error) {
+	base, err := p.percentage()
+	if err != nil {
+		return 0, err
+	}
+	if p.peek() != '^' {
+		return base, nil
+	}
+	p.pos++
+
+	exponent, err := p.unary()
+	if err != nil {
+		return 0, err
+	}
+	return math.Pow(base, exponent), nil
+}
+
+func (p *parser) percentage() (float64, error) {
+	value, err := p.primary()
+	if err == nil && p.peek() == '%' {
+		p.pos++
+		value /= 100
+	}
+	return value, err
+}
+
+func (p *parser) primary() (float64, error) {
+	if p.peek() == '(' {
+		p.pos++
+		value, err := p.expression()
+		if err != nil {
+			return 0, err
+		}
+		if p.peek() != ')' {
+			return 0, errors.New("missing closing parenthesis")
+		}
+		p.pos++
+		return value, nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.input) && (p.input[p.pos] >= '0' && p.input[p.pos] <= '9' || p.input[p.pos] == '.') {
+		p.pos++
+	}
+	if start == p.pos {
+		return 0, fmt.Errorf("expected a number in %q", p.input)
+	}
+	return strconv.ParseFloat(p.input[start:p.pos], 64)
+}
@@ -0,0 +1,56 @@
+package enhancedcontext
+
+import "testing"
+
+func TestCalculate(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"what's 12 times 7", "84"},
+		{"2 + 3 * 4", "14"},
+		{"(2 + 3) * 4", "20"},
+		{"10 - 4 - 3", "3"},
+		{"100 / 10 / 5", "2"},
+		{"2 ^ 3 ^ 2", "512"},
+		{"-2^2", "-4"},
+		{"what is -3 squared", "-9"},
+		{"minus 3 squared", "-9"},
+		{"(-3)^2", "9"},
+		{"2^-1", "0.5"},
+		{"4 cubed", "64"},
+		{"15 percent of 80", "12"},
+		{"3 x 4", "12"},
+		{"1,000 divided by 8", "125"},
+		{"1 / 3", "0.333333"},
+		{"-(2 + 3) * -2", "10"},
+	}
+
+	for _, tt := range tests {
+		got, err := calculate(tt.text)
+		if err != nil {
+			t.Errorf("calculate(%q): %v", tt.text, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("calculate(%q) = %s, want %s", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCalculateErrors(t *testing.T) {
+	for _, text := range []string{
+		"5 / 0",
+		"5 / (2 - 2)",
+		"(2 + 3",
+		"2 + * 3",
+		"3 +",
+		"1.2.3 + 1",
+		"what year was 1989",
+		"0 ^ -1",
+	} {
+		if got, err := calculate(text); err == nil {
+			t.Errorf("calculate(%q) = %s, want an error", text, got)
+		}
+	}
+}
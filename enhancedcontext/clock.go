@@ -0,0 +1,77 @@
+package enhancedcontext
+
+import (
+	"KevinGo/location"
+	"KevinGo/ollama"
+	"KevinGo/skill"
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+var asksForDate = regexp.MustCompile(`(?i)\b(date|day)\b`)
+
+// clockSkill tells the local time and date without asking the LLM.
+type clockSkill struct {
+	matcher skill.Matcher
+}
+
+func newClockSkill() *clockSkill {
+	return &clockSkill{
+		matcher: skill.Intent("time", skill.Any(
+			skill.Pattern(1, `(?i)\bwhat\s+time\s+is\s+it\b`),
+			skill.Pattern(1, `(?i)\bwhat(\s+is|'s)\s+the\s+(time|date)\b`),
+			skill.Pattern(1, `(?i)\bwhat(\s+is|'s)\s+today'?s\s+date\b`),
+			skill.Pattern(1, `(?i)\bwhat\s+(day|date)\s+is\s+(it|today)\b`),
+		)),
+	}
+}
+
+func (c *clockSkill) Name() string { return "time" }
+
+func (c *clockSkill) Description() string {
+	return "the current local time or today's date"
+}
+
+func (c *clockSkill) Match(ctx context.Context, query string) float64 {
+	return c.matcher.Score(ctx, query)
+}
+
+// Answer only knows the local time, so questions about the time in
+// another place are left to the LLM.
+func (c *clockSkill) Answer(ctx context.Context, query string) (string, error) {
+	if place, ok := location.Extract(query); ok && !place.Here {
+		return "", nil
+	}
+	if classification, ok := skill.ClassificationFrom(ctx); ok && classification.Entity("city") != "" {
+		return "", nil
+	}
+
+	now := time.Now()
+	if asksForDate.MatchString(query) {
+		return "Today is " + now.Format("Monday, 2 January 2006") + ".", nil
+	}
+	return "It's " + now.Format("3:04 PM") + ".", nil
+}
+
+func (c *clockSkill) Context(_ context.Context, query string) (string, error) {
+	return fmt.Sprintf(`
+TIME CONTEXT:
+🕒 Local time: %s
+
+INSTRUCTIONS:
+- This is the user's local time; if they asked about another place, say you only know the local time
+- Always respond in English as instructed in your main context`,
+		time.Now().Format("Monday, 2 January 2006, 15:04 MST")), nil
+}
+
+func (c *clockSkill) Definitions() []ollama.Tool {
+	return []ollama.Tool{
+		ollama.NewTool("get_time", "Get the user's current local date and time.", nil),
+	}
+}
+
+func (c *clockSkill) Call(context.Context, ollama.ToolCall) (string, error) {
+	return time.Now().Format("Monday, 2 January 2006, 15:04 MST"), nil
+}
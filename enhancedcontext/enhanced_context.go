@@ -1,54 +1,30 @@
 package enhancedcontext
 
 import (
+	"KevinGo/skill"
 	"KevinGo/weatherapi"
+	"context"
 	"fmt"
-	"regexp"
 	"strings"
+	"time"
 )
 
-func analyzeQueryType(query string) string {
-	lowerQuery := strings.ToLower(query)
+// Skills answers or adds context to the questions it recognizes. Weather
+// is registered first, so it wins ties.
+var Skills = skill.NewRegistry()
 
-	weatherKeywords := []string{"weather", "rain", "sun", "cold", "hot", "wind"}
-
-	for _, keyword := range weatherKeywords {
-		if strings.Contains(lowerQuery, keyword) {
-			return "weather"
-		}
-	}
-
-	return "general"
+func init() {
+	Skills.Register(newWeatherSkill())
+	Skills.Register(newClockSkill())
+	Skills.Register(newTimerSkill())
+	Skills.Register(newCalculatorSkill())
 }
 
-func extractCityFromQuery(query string) string {
-	lowerQuery := strings.ToLower(query)
-
-	patterns := []string{
-		`(?i)weather in (.+?)(\s|$|\?)`,
-		`(?i)how.+weather.+in (.+?)(\s|$|\?)`,
-		`(?i)temperature in (.+?)(\s|$|\?)`,
-	}
-
-	for _, pattern := range patterns {
-		re := regexp.MustCompile(pattern)
-		matches := re.FindStringSubmatch(query)
-		if len(matches) >= 2 {
-			city := strings.TrimSpace(matches[1])
-			city = strings.Trim(city, ".,!?")
-			return city
-		}
-	}
-
-	words := strings.Fields(lowerQuery)
-	for i, word := range words {
-		if (word == "in" || word == "în") && i+1 < len(words) {
-			return strings.TrimSpace(words[i+1])
-		}
-	}
-
-	return "Bucharest"
-}
+const generalContext = `
+GENERAL WEB CONTEXT:
+- Use the most recent information available
+- Verify and compare multiple sources when possible
+- Mention when information was last updated`
 
 func getClothingRecommendations(weather *weatherapi.WeatherData) string {
 	temp := weather.Temperature
@@ -71,7 +47,7 @@ func getClothingRecommendations(weather *weatherapi.WeatherData) string {
 		recommendations = append(recommendations, "👕 Light clothing, t-shirt, shorts, sandals or breathable shoes")
 	}
 
-	if precipitation > 0 || strings.Contains(description, "rain") || strings.Contains(description, "drizzle") {
+	if precipitation > 0 || weather.RainChance >= 50 || strings.Contains(description, "rain") || strings.Contains(description, "drizzle") {
 		recommendations = append(recommendations, "☔ Umbrella or raincoat, waterproof shoes")
 	}
 
@@ -94,24 +70,55 @@ func getClothingRecommendations(weather *weatherapi.WeatherData) string {
 	return strings.Join(recommendations, "\n• ")
 }
 
-func GetSpecializedContext(query string) string {
-	queryType := analyzeQueryType(query)
+// Prepare routes the question to the skill that handles it. The result
+// holds either the context for the prompt or, for skills that answer
+// directly, the complete reply. A skill left to its tool during tool
+// calling has neither, and the general context is kept out of its way.
+func Prepare(ctx context.Context, query string) skill.Result {
+	result, err := Skills.Handle(ctx, query)
+	if err != nil || result.Skill == "" {
+		return skill.Result{Context: generalContext}
+	}
+	return result
+}
+
+func GetSpecializedContext(ctx context.Context, query string) string {
+	result := Prepare(ctx, query)
+	if result.Answer != "" {
+		return fmt.Sprintf(`
+DIRECT ANSWER CONTEXT:
+%s
+
+INSTRUCTIONS:
+- Give the user this answer
+- Always respond in English as instructed in your main context`, result.Answer)
+	}
+	return result.Context
+}
 
-	switch queryType {
-	case "weather":
-		city := extractCityFromQuery(query)
+func weatherContext(ctx context.Context, query string, place weatherapi.Place) string {
+	city := place.String()
 
-		weather, err := weatherapi.GetWeather(city)
-		if err != nil {
-			return fmt.Sprintf(`
+	forecast, err := weatherapi.Provider.ForecastFor(ctx, place)
+	if err != nil {
+		return fmt.Sprintf(`
 WEATHER ERROR CONTEXT:
 Sorry, I couldn't get the weather data for %s. Error: %v
-Please try asking about weather for another city.`, city, err)
-		}
+%s`, city, err, weatherTrouble(err))
+	}
 
-		clothingRecommendations := getClothingRecommendations(weather)
+	now := time.Now().In(forecast.Current().Time.Location())
+	if p := parsePeriod(query, now); !p.isNow() {
+		return forecastContext(city, forecast, p)
+	}
 
-		return fmt.Sprintf(`
+	return currentWeatherContext(city, forecast.Current())
+}
+
+func currentWeatherContext(city string, weather weatherapi.WeatherData) string {
+	clothingRecommendations := getClothingRecommendations(&weather)
+
+	return fmt.Sprintf(`
 CURRENT WEATHER CONTEXT FOR %s:
 📍 Location: %s
 🗓️ Date/Time: %s
@@ -130,15 +137,50 @@ INSTRUCTIONS:
 - Mention that this is current/recent weather data
 - Be friendly and helpful in your response
 - Always respond in English as instructed in your main context`,
-			city, weather.Location, weather.Day, weather.Temperature,
-			weather.Description, weather.Humidity, weather.Wind,
-			weather.Precipitation, clothingRecommendations)
+		city, weather.Location, weather.Day, weather.Temperature,
+		weather.Description, weather.Humidity, weather.Wind,
+		weather.Precipitation, clothingRecommendations)
+}
 
-	default:
-		return `
-GENERAL WEB CONTEXT:
-- Use the most recent information available
-- Verify and compare multiple sources when possible
-- Mention when information was last updated`
+func forecastContext(city string, forecast *weatherapi.Forecast, p period) string {
+	slots := forecast.Between(p.start, p.end)
+	if len(slots) == 0 {
+		return fmt.Sprintf(`
+WEATHER FORECAST CONTEXT FOR %s:
+The forecast for %s only reaches %s, so there is no data for %s yet.
+
+INSTRUCTIONS:
+- Tell the user the forecast does not cover %s yet and suggest asking again closer to the date
+- Always respond in English as instructed in your main context`,
+			city, forecast.Location, forecast.End().Format("Monday 2 January"), p.label, p.label)
 	}
+
+	summary := summarize(slots)
+	clothingRecommendations := getClothingRecommendations(summary.asWeatherData())
+
+	return fmt.Sprintf(`
+WEATHER FORECAST CONTEXT FOR %s, %s:
+📍 Location: %s
+🗓️ Period: %s to %s
+🌡️ Temperature: %.1f°C to %.1f°C
+🌤️ Mostly: %s
+☔ Chance of rain: %.0f%%
+🌧️ Expected precipitation: %.1f mm
+💨 Wind up to: %.1f m/s
+💧 Humidity: %.0f%%
+
+CLOTHING RECOMMENDATIONS:
+• %s
+
+INSTRUCTIONS:
+- Present this forecast in a natural, conversational way
+- Make clear that it is the forecast for %s, not the current weather
+- Answer the user's actual question first, for example whether it will rain
+- Include the clothing recommendations as helpful advice
+- Always respond in English as instructed in your main context`,
+		strings.ToUpper(p.label), city, forecast.Location,
+		slots[0].Time.Format("Monday 2 January 15:04"), p.end.Format("Monday 2 January 15:04"),
+		summary.minTemp, summary.maxTemp, summary.conditions(), summary.rainChance,
+		summary.precipitation, summary.wind, summary.humidity,
+		clothingRecommendations, p.label)
 }
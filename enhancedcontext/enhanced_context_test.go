@@ -0,0 +1,26 @@
+package enhancedcontext
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPrepareLeavesToolsAlone(t *testing.T) {
+	Skills.ToolCalling = true
+	defer func() { Skills.ToolCalling = false }()
+
+	result := Prepare(context.Background(), "what's the weather in Cluj tomorrow")
+	if result.Skill != "weather" {
+		t.Fatalf("skill = %q, want weather", result.Skill)
+	}
+	if result.Context != "" || result.Answer != "" {
+		t.Errorf("result = %+v, want the question left to the tool", result)
+	}
+}
+
+func TestPrepareGeneralContext(t *testing.T) {
+	result := Prepare(context.Background(), "who wrote the odyssey")
+	if result.Skill != "" || result.Context != generalContext {
+		t.Errorf("result = %+v, want the general context", result)
+	}
+}
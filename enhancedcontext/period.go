@@ -0,0 +1,210 @@
+package enhancedcontext
+
+import (
+	"KevinGo/weatherapi"
+	"strings"
+	"time"
+)
+
+// period is the stretch of time a weather question is about. A zero
+// period means the question is about the weather right now.
+type period struct {
+	label      string
+	start, end time.Time
+}
+
+func (p period) isNow() bool {
+	return p.label == ""
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday,
+	"wednesday": time.Wednesday, "thursday": time.Thursday,
+	"friday": time.Friday, "saturday": time.Saturday,
+}
+
+// partsOfDay are the hours a part of the day covers; night runs into the
+// following morning.
+var partsOfDay = []struct {
+	name       string
+	start, end int
+}{
+	{"morning", 6, 12},
+	{"afternoon", 12, 18},
+	{"evening", 18, 24},
+	{"night", 18, 30},
+}
+
+// parsePeriod finds a relative time expression such as "tonight",
+// "tomorrow morning", "on Friday" or "this weekend" in the question.
+// now must be in the forecast location's time zone.
+func parsePeriod(query string, now time.Time) period {
+	words := strings.Fields(strings.ToLower(query))
+	for i, word := range words {
+		words[i] = strings.Trim(word, ".,!?;:'\"")
+	}
+	has := func(word string) bool {
+		for _, w := range words {
+			if w == word {
+				return true
+			}
+		}
+		return false
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var p period
+	switch {
+	case has("tonight"):
+		p = period{label: "tonight", start: today.Add(18 * time.Hour), end: today.Add(30 * time.Hour)}
+
+	case has("weekend"):
+		saturday := today.AddDate(0, 0, (int(time.Saturday)-int(now.Weekday())+7)%7)
+		if now.Weekday() == time.Sunday {
+			saturday = today.AddDate(0, 0, -1)
+		}
+		p = period{label: "this weekend", start: saturday, end: saturday.AddDate(0, 0, 2)}
+
+	case strings.Contains(strings.Join(words, " "), "day after tomorrow"):
+		p = dayPeriod(today.AddDate(0, 0, 2), "the day after tomorrow", words)
+
+	case has("tomorrow"):
+		p = dayPeriod(today.AddDate(0, 0, 1), "tomorrow", words)
+
+	case has("today") || (has("this") && mentionsPartOfDay(words)):
+		p = dayPeriod(today, "today", words)
+
+	default:
+		for _, word := range words {
+			if weekday, ok := weekdays[word]; ok {
+				day := today.AddDate(0, 0, (int(weekday)-int(now.Weekday())+7)%7)
+				p = dayPeriod(day, day.Weekday().String(), words)
+				break
+			}
+			// Dates from the classifier, such as "2026-10-20".
+			if day, err := time.ParseInLocation(time.DateOnly, word, now.Location()); err == nil {
+				p = dayPeriod(day, day.Format("Monday 2 January"), words)
+				break
+			}
+		}
+	}
+
+	if p.isNow() {
+		return p
+	}
+
+	// Only the part of the period that is still ahead matters.
+	if hour := now.Truncate(time.Hour); p.start.Before(hour) {
+		p.start = hour
+	}
+	if !p.start.Before(p.end) {
+		return period{}
+	}
+
+	return p
+}
+
+// dayPeriod covers the whole day, or the part of it named in the
+// question.
+func dayPeriod(day time.Time, label string, words []string) period {
+	for _, part := range partsOfDay {
+		for _, word := range words {
+			if word == part.name {
+				if label == "today" {
+					label = "this " + part.name
+				} else {
+					label += " " + part.name
+				}
+				return period{
+					label: label,
+					start: day.Add(time.Duration(part.start) * time.Hour),
+					end:   day.Add(time.Duration(part.end) * time.Hour),
+				}
+			}
+		}
+	}
+
+	return period{label: label, start: day, end: day.AddDate(0, 0, 1)}
+}
+
+func mentionsPartOfDay(words []string) bool {
+	for _, part := range partsOfDay {
+		for _, word := range words {
+			if word == part.name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// weatherSummary aggregates the forecast slots of a period.
+type weatherSummary struct {
+	minTemp, maxTemp float32
+	// rainChance is the highest chance of any slot.
+	rainChance    float32
+	precipitation float32
+	// condition is the most common description and wettest the one of
+	// the slot with the most precipitation, when that differs.
+	condition string
+	wettest   string
+	wind      float32
+	humidity  float32
+}
+
+func summarize(slots []weatherapi.WeatherData) weatherSummary {
+	summary := weatherSummary{
+		minTemp: slots[0].Temperature,
+		maxTemp: slots[0].Temperature,
+	}
+
+	counts := map[string]int{}
+	var wettest float32
+	for _, slot := range slots {
+		summary.minTemp = min(summary.minTemp, slot.Temperature)
+		summary.maxTemp = max(summary.maxTemp, slot.Temperature)
+		summary.rainChance = max(summary.rainChance, slot.RainChance)
+		summary.precipitation += slot.Precipitation
+		summary.wind = max(summary.wind, slot.Wind)
+		summary.humidity += slot.Humidity
+
+		if slot.Precipitation > wettest {
+			wettest = slot.Precipitation
+			summary.wettest = slot.Description
+		}
+
+		counts[slot.Description]++
+		if summary.condition == "" || counts[slot.Description] > counts[summary.condition] {
+			summary.condition = slot.Description
+		}
+	}
+	summary.humidity /= float32(len(slots))
+	if summary.wettest == summary.condition {
+		summary.wettest = ""
+	}
+
+	return summary
+}
+
+// conditions describes the period, such as "partly cloudy, with light
+// rain at times".
+func (s weatherSummary) conditions() string {
+	if s.wettest == "" {
+		return s.condition
+	}
+	return s.condition + ", with " + s.wettest + " at times"
+}
+
+// asWeatherData lets the clothing recommendations dress for the coldest
+// and wettest part of the period.
+func (s weatherSummary) asWeatherData() *weatherapi.WeatherData {
+	return &weatherapi.WeatherData{
+		Temperature:   s.minTemp,
+		Description:   s.conditions(),
+		Precipitation: s.precipitation,
+		Wind:          s.wind,
+		Humidity:      s.humidity,
+		RainChance:    s.rainChance,
+	}
+}
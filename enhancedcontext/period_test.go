@@ -0,0 +1,74 @@
+package enhancedcontext
+
+import (
+	"KevinGo/weatherapi"
+	"testing"
+	"time"
+)
+
+func TestParsePeriod(t *testing.T) {
+	zone := time.FixedZone("EEST", 3*60*60)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, time.October, day, hour, minute, 0, 0, zone)
+	}
+	thursday := at(15, 14, 20)
+
+	tests := []struct {
+		query      string
+		now        time.Time
+		label      string
+		start, end time.Time
+	}{
+		{query: "What's the weather in Cluj?", now: thursday},
+		{query: "What's the weather today?", now: thursday, label: "today", start: at(15, 14, 0), end: at(16, 0, 0)},
+		{query: "Will it rain this evening?", now: thursday, label: "this evening", start: at(15, 18, 0), end: at(16, 0, 0)},
+		{query: "how cold was it this morning", now: thursday},
+		{query: "Is it cold tonight?", now: thursday, label: "tonight", start: at(15, 18, 0), end: at(16, 6, 0)},
+		{query: "Tonight?", now: at(15, 23, 40), label: "tonight", start: at(15, 23, 0), end: at(16, 6, 0)},
+		{query: "weather tomorrow morning", now: thursday, label: "tomorrow morning", start: at(16, 6, 0), end: at(16, 12, 0)},
+		{query: "and the day after tomorrow?", now: thursday, label: "the day after tomorrow", start: at(17, 0, 0), end: at(18, 0, 0)},
+		{query: "Do I need a coat on Friday night?", now: thursday, label: "Friday night", start: at(16, 18, 0), end: at(17, 6, 0)},
+		{query: "What about Thursday?", now: thursday, label: "Thursday", start: at(15, 14, 0), end: at(16, 0, 0)},
+		{query: "Monday", now: thursday, label: "Monday", start: at(19, 0, 0), end: at(20, 0, 0)},
+		{query: "Any rain this weekend?", now: thursday, label: "this weekend", start: at(17, 0, 0), end: at(19, 0, 0)},
+		{query: "the weekend", now: at(18, 9, 5), label: "this weekend", start: at(18, 9, 0), end: at(19, 0, 0)},
+		{query: "weather 2026-10-20", now: thursday, label: "Tuesday 20 October", start: at(20, 0, 0), end: at(21, 0, 0)},
+	}
+
+	for _, tt := range tests {
+		p := parsePeriod(tt.query, tt.now)
+		if p.label != tt.label || !p.start.Equal(tt.start) || !p.end.Equal(tt.end) {
+			t.Errorf("%q at %s = %q %s–%s, want %q %s–%s", tt.query, tt.now.Format("Mon 15:04"),
+				p.label, p.start.Format("Mon 15:04"), p.end.Format("Mon 15:04"),
+				tt.label, tt.start.Format("Mon 15:04"), tt.end.Format("Mon 15:04"))
+		}
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	slots := []weatherapi.WeatherData{
+		{Temperature: 9, Description: "overcast", Humidity: 70, Wind: 3},
+		{Temperature: 12, Description: "light rain", Precipitation: 1.5, RainChance: 80, Humidity: 90, Wind: 6},
+		{Temperature: 14, Description: "overcast", Precipitation: 0.2, RainChance: 40, Humidity: 80, Wind: 4},
+	}
+
+	s := summarize(slots)
+	if s.minTemp != 9 || s.maxTemp != 14 {
+		t.Errorf("temperatures = %v–%v, want 9–14", s.minTemp, s.maxTemp)
+	}
+	if s.rainChance != 80 || s.precipitation != 1.7 || s.wind != 6 || s.humidity != 80 {
+		t.Errorf("summary = %+v, want the highest chance and wind, total rain and mean humidity", s)
+	}
+	if got := s.conditions(); got != "overcast, with light rain at times" {
+		t.Errorf("conditions = %q", got)
+	}
+
+	weather := s.asWeatherData()
+	if weather.Temperature != 9 || weather.Description != s.conditions() {
+		t.Errorf("asWeatherData = %+v, want the coldest temperature", weather)
+	}
+
+	if got := summarize(slots[:1]).conditions(); got != "overcast" {
+		t.Errorf("one dry slot: conditions = %q, want overcast", got)
+	}
+}
@@ -0,0 +1,258 @@
+package enhancedcontext
+
+import (
+	"KevinGo/ollama"
+	"KevinGo/skill"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OnTimer is called with an announcement such as "Your tea timer is
+// done." when a timer goes off. main prints and speaks it.
+var OnTimer = func(announcement string) {}
+
+var (
+	durationPart = regexp.MustCompile(`(?i)\b(\d+(?:\.\d+)?|an?|one|two|three|four|five|six|seven|eight|nine|ten|fifteen|twenty|thirty|forty|fifty|sixty|half an?)\s*(seconds?|secs?|minutes?|mins?|hours?|hrs?)\b`)
+	// cancelTimers only matches when the verb is about the timer, so "set
+	// a timer for 10 minutes to stop the pasta" sets one.
+	cancelTimers = regexp.MustCompile(`(?i)\b(cancel|stop|clear)\s+(the|my|all)?\s*(\w+\s+){0,2}timers?\b`)
+	timerLabel   = regexp.MustCompile(`(?i)\b(?:set|start)\s+(?:a|an|the|my)?\s*([a-z]+)\s+timer\b`)
+)
+
+var numberWords = map[string]float64{
+	"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5,
+	"six": 6, "seven": 7, "eight": 8, "nine": 9, "ten": 10, "fifteen": 15,
+	"twenty": 20, "thirty": 30, "forty": 40, "fifty": 50, "sixty": 60,
+	"half a": 0.5, "half an": 0.5,
+}
+
+// timerSkill runs countdown timers and announces them through OnTimer.
+type timerSkill struct {
+	matcher skill.Matcher
+
+	mu     sync.Mutex
+	timers map[int]*countdown
+	next   int
+}
+
+type countdown struct {
+	label string
+	ends  time.Time
+	timer *time.Timer
+}
+
+func newTimerSkill() *timerSkill {
+	return &timerSkill{
+		matcher: skill.Intent("timer", skill.Any(
+			skill.Pattern(1, `(?i)\b(set|start)\s+(a|an|the|my)?\s*([\w-]+\s+){0,2}timer\b`),
+			skill.Pattern(1, `(?i)\btimer\s+for\b`),
+			skill.Pattern(1, cancelTimers.String()),
+			skill.Pattern(1, `(?i)\bhow\s+(much\s+time|long)\s+(is\s+)?left\b`),
+		)),
+		timers: map[int]*countdown{},
+	}
+}
+
+func (t *timerSkill) Name() string { return "timer" }
+
+func (t *timerSkill) Description() string {
+	return "setting, cancelling or checking countdown timers"
+}
+
+func (t *timerSkill) Match(ctx context.Context, query string) float64 {
+	return t.matcher.Score(ctx, query)
+}
+
+// Answer sets, cancels or reports timers straight from the question.
+func (t *timerSkill) Answer(_ context.Context, query string) (string, error) {
+	d, err := parseDuration(query)
+	if cancelTimers.MatchString(query) {
+		// "cancel the 5 minute timer" names a duration too.
+		return t.cancel(), nil
+	}
+	if err != nil {
+		return t.status(), nil
+	}
+
+	label := ""
+	if m := timerLabel.FindStringSubmatch(query); m != nil && !isTimerFiller(m[1]) {
+		label = strings.ToLower(m[1])
+	}
+	return t.start(d, label), nil
+}
+
+// isTimerFiller reports whether the word before "timer" is an article or a
+// unit, as in "set a timer" or "set a 5 minute timer", rather than a label.
+func isTimerFiller(word string) bool {
+	switch strings.ToLower(word) {
+	case "a", "an", "the", "my", "new":
+		return true
+	}
+	return durationPart.MatchString("1 " + word)
+}
+
+func (t *timerSkill) Context(_ context.Context, query string) (string, error) {
+	return fmt.Sprintf(`
+TIMER CONTEXT:
+%s`, t.status()), nil
+}
+
+func (t *timerSkill) Definitions() []ollama.Tool {
+	return []ollama.Tool{
+		ollama.NewTool("set_timer", "Start a countdown timer that announces when it is done.",
+			map[string]ollama.ToolProperty{
+				"duration": {Type: "string", Description: `How long, for example "5 minutes" or "1 hour 30 minutes"`},
+				"label":    {Type: "string", Description: `What the timer is for, for example "tea". Optional.`},
+			}, "duration"),
+		ollama.NewTool("cancel_timers", "Cancel all running timers.", nil),
+		ollama.NewTool("list_timers", "List the running timers and how long each has left.", nil),
+	}
+}
+
+func (t *timerSkill) Call(_ context.Context, call ollama.ToolCall) (string, error) {
+	switch call.Function.Name {
+	case "set_timer":
+		var args struct {
+			Duration string `json:"duration"`
+			Label    string `json:"label"`
+		}
+		if err := call.DecodeArguments(&args); err != nil {
+			return "", err
+		}
+		d, err := parseDuration(args.Duration)
+		if err != nil {
+			return "", err
+		}
+		return t.start(d, args.Label), nil
+
+	case "cancel_timers":
+		return t.cancel(), nil
+
+	default:
+		return t.status(), nil
+	}
+}
+
+func (t *timerSkill) start(d time.Duration, label string) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	id := t.next
+	t.next++
+
+	name := label
+	if name == "" {
+		name = formatDuration(d)
+	}
+
+	c := &countdown{label: name, ends: time.Now().Add(d)}
+	c.timer = time.AfterFunc(d, func() {
+		t.mu.Lock()
+		delete(t.timers, id)
+		t.mu.Unlock()
+		OnTimer(fmt.Sprintf("Your %s timer is done.", name))
+	})
+	t.timers[id] = c
+
+	if label != "" {
+		return fmt.Sprintf("Timer for %s set for %s.", label, formatDuration(d))
+	}
+	return fmt.Sprintf("Timer set for %s.", formatDuration(d))
+}
+
+func (t *timerSkill) cancel() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.timers) == 0 {
+		return "There are no timers running."
+	}
+	for id, c := range t.timers {
+		c.timer.Stop()
+		delete(t.timers, id)
+	}
+	return "All timers cancelled."
+}
+
+func (t *timerSkill) status() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.timers) == 0 {
+		return "There are no timers running."
+	}
+
+	var running []*countdown
+	for _, c := range t.timers {
+		running = append(running, c)
+	}
+	sort.Slice(running, func(i, j int) bool { return running[i].ends.Before(running[j].ends) })
+
+	parts := make([]string, len(running))
+	for i, c := range running {
+		left := time.Until(c.ends).Round(time.Second)
+		parts[i] = fmt.Sprintf("the %s timer has %s left", c.label, formatDuration(left))
+	}
+	return strings.ToUpper(parts[0][:1]) + strings.Join(parts, "; ")[1:] + "."
+}
+
+// parseDuration reads durations such as "5 minutes", "half an hour",
+// "1 hour and 30 minutes" or "90s".
+func parseDuration(text string) (time.Duration, error) {
+	if d, err := time.ParseDuration(strings.ReplaceAll(text, " ", "")); err == nil && d > 0 {
+		return d, nil
+	}
+
+	var total time.Duration
+	for _, m := range durationPart.FindAllStringSubmatch(text, -1) {
+		amount, ok := numberWords[strings.ToLower(m[1])]
+		if !ok {
+			amount, _ = strconv.ParseFloat(m[1], 64)
+		}
+
+		unit := time.Second
+		switch strings.ToLower(m[2])[0] {
+		case 'm':
+			unit = time.Minute
+		case 'h':
+			unit = time.Hour
+		}
+		total += time.Duration(amount * float64(unit))
+	}
+
+	if total <= 0 {
+		return 0, errors.New("no duration given")
+	}
+	return total, nil
+}
+
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+	seconds := int(d.Seconds()) % 60
+
+	var parts []string
+	add := func(n int, unit string) {
+		if n == 1 {
+			parts = append(parts, "1 "+unit)
+		} else if n > 1 {
+			parts = append(parts, fmt.Sprintf("%d %ss", n, unit))
+		}
+	}
+	add(hours, "hour")
+	add(minutes, "minute")
+	add(seconds, "second")
+
+	if len(parts) == 0 {
+		return "0 seconds"
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,44 @@
+package enhancedcontext
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTimerAnswer(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"set a timer for 10 minutes to stop the pasta", "Timer set for 10 minutes."},
+		{"set a pasta timer for 10 minutes", "Timer for pasta set for 10 minutes."},
+		{"how much time is left", ""},
+		{"stop the 10 minute timer", "All timers cancelled."},
+		{"cancel my timers", "There are no timers running."},
+	}
+
+	timers := newTimerSkill()
+	defer timers.cancel()
+
+	for _, tt := range tests {
+		got, err := timers.Answer(context.Background(), tt.query)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.query, err)
+		}
+		if tt.want != "" && got != tt.want {
+			t.Errorf("%q = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestTimerMatchesCancel(t *testing.T) {
+	timers := newTimerSkill()
+	for _, query := range []string{"cancel the timer", "stop all timers", "clear my pasta timer"} {
+		if timers.Match(context.Background(), query) == 0 {
+			t.Errorf("%q did not match the timer skill", query)
+		}
+	}
+	if timers.Match(context.Background(), "stop the music") != 0 {
+		t.Errorf(`"stop the music" matched the timer skill`)
+	}
+}
@@ -0,0 +1,176 @@
+package enhancedcontext
+
+import (
+	"KevinGo/apierror"
+	"KevinGo/location"
+	"KevinGo/ollama"
+	"KevinGo/skill"
+	"KevinGo/weatherapi"
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Locations finds the place a weather question is about. Its Home is set
+// from the configuration.
+var Locations = &location.Resolver{Geocoder: &weatherapi.OpenMeteo{}}
+
+// weatherSkill adds the forecast for the place and time a question is
+// about, and asks which place was meant when that is unclear.
+type weatherSkill struct {
+	matcher skill.Matcher
+	// followUp is the weather question that is waiting for the user to say
+	// which place they meant.
+	followUp *pendingQuestion
+}
+
+// pendingQuestion keeps the time the question was about; when is the
+// question itself unless the classifier named the date.
+type pendingQuestion struct {
+	when       string
+	candidates []weatherapi.Place
+}
+
+// Words such as "cold" or "hot" only count together with another hint,
+// so "I have a cold" is not a weather question but "is it cold outside"
+// is. A confident classifier overrides the keywords.
+func newWeatherSkill() *weatherSkill {
+	return &weatherSkill{
+		matcher: skill.Intent("weather", skill.Any(
+			skill.Keywords(1,
+				"weather", "forecast", "temperature", "temperatures", "umbrella",
+				"raining", "rainy", "snowing", "snowy", "sunny", "cloudy", "windy",
+				"humid", "humidity", "drizzle", "thunderstorm", "thunderstorms",
+				"foggy", "celsius", "fahrenheit"),
+			skill.Keywords(0.3,
+				"rain", "snow", "sun", "wind", "cold", "hot", "warm", "chilly",
+				"freezing", "storm", "fog", "outside", "degrees", "jacket", "coat"),
+			skill.Pattern(0.9, `(?i)\b(is|will|does|did)\s+it\s+(be\s+|going\s+to\s+(be\s+)?)?(rain|snow|sunny|cold|hot|warm|windy|freezing|storm)`),
+			skill.Pattern(0.9, `(?i)\bhow\s+(cold|hot|warm)\s+is\s+it\b`),
+			skill.Pattern(0.9, `(?i)\bwhat\s+should\s+i\s+wear\b`),
+		)),
+	}
+}
+
+func (w *weatherSkill) Name() string { return "weather" }
+
+func (w *weatherSkill) Description() string {
+	return "current weather or forecast, temperature, rain, or what to wear"
+}
+
+func (w *weatherSkill) Match(ctx context.Context, query string) float64 {
+	return w.matcher.Score(ctx, query)
+}
+
+// FollowUp completes the question asked before "which Paris do you mean?"
+// with the user's answer. Anything else drops it.
+func (w *weatherSkill) FollowUp(ctx context.Context, query string) (string, bool) {
+	pending := w.followUp
+	if pending == nil {
+		return "", false
+	}
+	w.followUp = nil
+
+	result, err := Locations.Choose(ctx, query, pending.candidates)
+	if err != nil || result.Place == nil {
+		return "", false
+	}
+	return weatherContext(ctx, pending.when, *result.Place), true
+}
+
+// Context looks up the place and time the classifier found in the
+// question, or reads them from the question itself.
+func (w *weatherSkill) Context(ctx context.Context, query string) (string, error) {
+	place, _ := location.Extract(query)
+	when := query
+	if c, ok := skill.ClassificationFrom(ctx); ok {
+		if city := c.Entity("city"); city != "" {
+			place = placeQuery(city)
+		}
+		if date := c.Entity("date"); date != "" {
+			when = date
+		}
+	}
+
+	result, err := Locations.Resolve(ctx, place)
+	if err != nil {
+		return fmt.Sprintf(`
+WEATHER ERROR CONTEXT:
+Sorry, I couldn't look up %s. Error: %v
+%s`, place, err, weatherTrouble(err)), nil
+	}
+
+	if result.Question != "" {
+		w.followUp = &pendingQuestion{when: when, candidates: result.Candidates}
+		return fmt.Sprintf(`
+WEATHER LOCATION CONTEXT:
+The user asked about the weather, but it is not clear for which place.
+
+INSTRUCTIONS:
+- Ask the user this and nothing else: %s
+- Do not guess the weather for any place
+- Always respond in English as instructed in your main context`, result.Question), nil
+	}
+
+	return weatherContext(ctx, when, *result.Place), nil
+}
+
+func (w *weatherSkill) Definitions() []ollama.Tool {
+	return []ollama.Tool{
+		ollama.NewTool("get_weather", "Get the current weather or the forecast for a place, with clothing advice.",
+			map[string]ollama.ToolProperty{
+				"city": {Type: "string", Description: `The place, for example "Cluj-Napoca" or "Paris, Texas". Leave empty for the user's home.`},
+				"when": {Type: "string", Description: `The time asked about, for example "now", "tomorrow morning", "Friday", "this weekend" or "2026-10-20"`},
+			}),
+	}
+}
+
+// Call looks up the weather for the model. When the place is unclear the
+// result asks the model to ask the user, who answers in the next turn.
+func (w *weatherSkill) Call(ctx context.Context, call ollama.ToolCall) (string, error) {
+	var args struct {
+		City string `json:"city"`
+		When string `json:"when"`
+	}
+	if err := call.DecodeArguments(&args); err != nil {
+		return "", err
+	}
+
+	var place location.Query
+	if args.City != "" {
+		place = placeQuery(args.City)
+	}
+
+	result, err := Locations.Resolve(ctx, place)
+	if err != nil {
+		return "", err
+	}
+	if result.Question != "" {
+		return "The place is unclear. Ask the user: " + result.Question, nil
+	}
+
+	return weatherContext(ctx, args.When, *result.Place), nil
+}
+
+// placeQuery reads a place named by the classifier, which may also be
+// "here" or "home".
+func placeQuery(name string) location.Query {
+	if q, ok := location.Extract("in " + name); ok && q.Here {
+		return q
+	}
+	return location.Parse(name)
+}
+
+// weatherTrouble tells the model what the user can do about a failed
+// lookup.
+func weatherTrouble(err error) string {
+	switch {
+	case errors.Is(err, apierror.ErrAuth):
+		return "The weather service rejected the API key. Tell the user to check weather.api_key."
+	case errors.Is(err, apierror.ErrRateLimited):
+		return "The weather service is limiting requests. Ask the user to try again in a minute."
+	case errors.Is(err, apierror.ErrTimeout), errors.Is(err, apierror.ErrUnavailable):
+		return "The weather service is not responding. Ask the user to try again later."
+	}
+	return "Please try asking about weather for another city."
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"KevinGo/apierror"
+	"KevinGo/stt"
+	"errors"
+)
+
+// explain turns a failed turn into what Kira tells the user, so they know
+// whether to repeat themselves, wait or fix the configuration.
+func explain(err error) string {
+	switch {
+	case errors.Is(err, stt.ErrNoSpeech):
+		return "I didn't catch any words. Please say that again."
+	case errors.Is(err, apierror.ErrAuth):
+		return "The speech service rejected my API key. Please check assemblyai.api_key."
+	case errors.Is(err, apierror.ErrRateLimited):
+		return "The speech service is limiting my requests or is out of credit. Please wait a moment and try again."
+	case errors.Is(err, apierror.ErrTimeout):
+		return "The speech service took too long to answer. Please try again."
+	case errors.Is(err, apierror.ErrUnavailable):
+		return "The speech service is having problems right now. Please try again later."
+	}
+	return "Sorry, something went wrong. Please try again."
+}
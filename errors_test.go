@@ -0,0 +1,31 @@
+package main
+
+import (
+	"KevinGo/apierror"
+	"KevinGo/stt"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{fmt.Errorf("transcription failed: %w", stt.ErrNoSpeech), "I didn't catch any words. Please say that again."},
+		{&apierror.StatusError{Service: "AssemblyAI", StatusCode: 401}, "The speech service rejected my API key. Please check assemblyai.api_key."},
+		{&apierror.StatusError{Service: "AssemblyAI", StatusCode: 402}, "The speech service is limiting my requests or is out of credit. Please wait a moment and try again."},
+		{apierror.Transport("AssemblyAI upload", context.DeadlineExceeded), "The speech service took too long to answer. Please try again."},
+		{&apierror.StatusError{Service: "AssemblyAI", StatusCode: 503}, "The speech service is having problems right now. Please try again later."},
+		{&apierror.StatusError{Service: "AssemblyAI", StatusCode: 400}, "Sorry, something went wrong. Please try again."},
+		{errors.New("microphone unplugged"), "Sorry, something went wrong. Please try again."},
+	}
+
+	for _, tt := range tests {
+		if got := explain(tt.err); got != tt.want {
+			t.Errorf("explain(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
@@ -3,15 +3,18 @@ module KevinGo
 go 1.23.4
 
 require (
-	github.com/go-audio/audio v1.0.0
 	github.com/go-audio/wav v1.1.0
 	github.com/gordonklaus/portaudio v0.0.0-20250206071425-98a94950218b
+	github.com/gorilla/websocket v1.5.3
 	github.com/hajimehoshi/go-mp3 v0.3.3
 	github.com/hajimehoshi/oto v0.7.1
 	github.com/hegedustibor/htgo-tts v0.0.0-20240912200108-467b3e535435
+	golang.org/x/text v0.28.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
+	github.com/go-audio/audio v1.0.0 // indirect
 	github.com/go-audio/riff v1.0.0 // indirect
 	github.com/hajimehoshi/oto/v2 v2.2.0 // indirect
 	golang.org/x/exp v0.0.0-20190306152737-a1d7652674e8 // indirect
@@ -0,0 +1,85 @@
+package intent
+
+import (
+	"KevinGo/ollama"
+	"KevinGo/skill"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Classifier asks the Ollama model what a question is about, with its
+// reply constrained to JSON. It implements skill.Classifier.
+type Classifier struct {
+	// Timeout bounds the model's answer, so a slow model does not delay
+	// the reply; the keyword matchers are used instead.
+	Timeout time.Duration
+}
+
+const prompt = `You classify requests made to a voice assistant.
+Reply with a JSON object only, in this form:
+{"intent": "<one of the intents>", "entities": {"city": "<place>", "date": "<time>"}, "confidence": <0 to 1>}
+
+Intents:
+%s
+Entities:
+- city: the place the request is about, as the user said it, for example "Paris, Texas"
+- date: the time the request is about, as the user said it, for example "tomorrow morning", "on Friday" or "this weekend"
+Leave out entities the user did not mention. Do not guess them.
+confidence is how sure you are of the intent.`
+
+// classifyOptions make the reading deterministic and leave room for the
+// JSON object however short the spoken answers are capped.
+var classifyOptions = ollama.Options{Temperature: ollama.Float(0), NumPredict: 256}
+
+// reply is what the model is asked for. Entities are decoded loosely
+// because small models sometimes answer with null or a number.
+type reply struct {
+	Intent     string                 `json:"intent"`
+	Entities   map[string]interface{} `json:"entities"`
+	Confidence float64                `json:"confidence"`
+}
+
+func (c *Classifier) Classify(ctx context.Context, query string, intents []skill.IntentInfo) (skill.Classification, error) {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	var list strings.Builder
+	known := map[string]bool{}
+	for _, intent := range intents {
+		fmt.Fprintf(&list, "- %s: %s\n", intent.Name, intent.Description)
+		known[intent.Name] = true
+	}
+
+	messages := []ollama.ChatMessage{
+		{Role: "system", Content: fmt.Sprintf(prompt, list.String())},
+		{Role: "user", Content: query},
+	}
+
+	var r reply
+	if err := ollama.ChatJSON(ctx, messages, &r, classifyOptions); err != nil {
+		return skill.Classification{}, fmt.Errorf("intent classification failed: %w", err)
+	}
+
+	name := strings.ToLower(strings.TrimSpace(r.Intent))
+	if !known[name] {
+		return skill.Classification{}, fmt.Errorf("intent classification failed: unknown intent %q", r.Intent)
+	}
+
+	entities := map[string]string{}
+	for key, value := range r.Entities {
+		if text, ok := value.(string); ok && strings.TrimSpace(text) != "" {
+			entities[strings.ToLower(key)] = text
+		}
+	}
+
+	return skill.Classification{
+		Intent:     name,
+		Entities:   entities,
+		Confidence: min(max(r.Confidence, 0), 1),
+	}, nil
+}
@@ -0,0 +1,143 @@
+package intent_test
+
+import (
+	"KevinGo/intent"
+	"KevinGo/ollama"
+	"KevinGo/skill"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var intents = []skill.IntentInfo{
+	{Name: skill.General, Description: "anything else"},
+	{Name: "weather", Description: "the weather"},
+	{Name: "timer", Description: "timers"},
+}
+
+// withModel points the package-level Ollama client at a stand-in whose
+// model replies with content, after delay.
+func withModel(t *testing.T, content string, delay time.Duration) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollama.ChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Format != "json" {
+			t.Errorf("request = %+v, %v; want a JSON chat", req, err)
+		}
+		if len(req.Messages) != 2 || !strings.Contains(req.Messages[0].Content, "- weather: the weather") {
+			t.Errorf("messages = %+v, want the intents listed in the system prompt", req.Messages)
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		json.NewEncoder(w).Encode(ollama.ChatResponse{
+			Message: ollama.ChatMessage{Role: "assistant", Content: content},
+			Done:    true,
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	saved := ollama.DefaultClient
+	ollama.DefaultClient = ollama.NewClient(srv.URL, "llama3.2")
+	ollama.DefaultClient.Retries = 0
+	t.Cleanup(func() { ollama.DefaultClient = saved })
+}
+
+func TestClassify(t *testing.T) {
+	withModel(t, `{"intent": " Weather ", "entities": {"city": "Paris, Texas", "date": "tomorrow", "Country": null, "days": 2}, "confidence": 1.4}`, 0)
+
+	c := &intent.Classifier{}
+	got, err := c.Classify(context.Background(), "Will it rain in Paris, Texas tomorrow?", intents)
+	if err != nil {
+		t.Fatalf("Classify: %v", err)
+	}
+
+	want := skill.Classification{
+		Intent:     "weather",
+		Entities:   map[string]string{"city": "Paris, Texas", "date": "tomorrow"},
+		Confidence: 1,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Classify = %+v, want %+v", got, want)
+	}
+}
+
+func TestClassifyErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		timeout time.Duration
+		delay   time.Duration
+		want    string
+	}{
+		{name: "unknown intent", content: `{"intent": "music", "confidence": 0.9}`, want: `unknown intent "music"`},
+		{name: "malformed JSON", content: `{"intent": "weather", `, want: "valid JSON"},
+		{name: "not JSON", content: `The intent is weather.`, want: "valid JSON"},
+		{name: "slow model", content: `{"intent": "weather", "confidence": 0.9}`, timeout: 50 * time.Millisecond, delay: 300 * time.Millisecond, want: "did not answer in time"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withModel(t, tt.content, tt.delay)
+
+			c := &intent.Classifier{Timeout: tt.timeout}
+			_, err := c.Classify(context.Background(), "what's the weather", intents)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want one mentioning %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// An unsure reading is ignored and the keywords decide.
+func TestLowConfidenceFallsBackToKeywords(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{`{"intent": "general", "confidence": 0.2}`, "weather"},
+		{`{"intent": "general", "confidence": 0.95}`, ""},
+		{`{"intent": "timer", "confidence": 0.4}`, "weather"},
+	}
+
+	for _, tt := range tests {
+		withModel(t, tt.content, 0)
+
+		r := skill.NewRegistry()
+		r.Classifier = &intent.Classifier{}
+		r.Register(&keywordSkill{name: "weather", words: []string{"weather"}})
+		r.Register(&keywordSkill{name: "timer", words: []string{"timer"}})
+
+		got, err := r.Handle(context.Background(), "weather tomorrow")
+		if err != nil {
+			t.Fatalf("Handle: %v", err)
+		}
+		if got.Skill != tt.want {
+			t.Errorf("reply %s: skill = %q, want %q", tt.content, got.Skill, tt.want)
+		}
+	}
+}
+
+type keywordSkill struct {
+	name  string
+	words []string
+}
+
+func (k *keywordSkill) Name() string        { return k.name }
+func (k *keywordSkill) Description() string { return "the " + k.name }
+
+func (k *keywordSkill) Match(ctx context.Context, query string) float64 {
+	return skill.Intent(k.name, skill.Keywords(1, k.words...)).Score(ctx, query)
+}
+
+func (k *keywordSkill) Context(context.Context, string) (string, error) {
+	return k.name + " context", nil
+}
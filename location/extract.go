@@ -0,0 +1,228 @@
+package location
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Query is a place as the user named it. "Paris, Texas" has the Name
+// "Paris" and the Qualifier "Texas", which narrows down the matches.
+type Query struct {
+	Name      string
+	Qualifier string
+	// Here is set for "here" and "at home", which mean the home location.
+	Here bool
+}
+
+func (q Query) IsZero() bool {
+	return q.Name == "" && !q.Here
+}
+
+func (q Query) String() string {
+	if q.Qualifier == "" {
+		return q.Name
+	}
+	return q.Name + ", " + q.Qualifier
+}
+
+// Parse splits a place name such as "Paris, Texas" or "Cluj-Napoca,
+// Romania" into a Query.
+func Parse(name string) Query {
+	name, qualifier, _ := strings.Cut(name, ",")
+	return Query{
+		Name:      strings.TrimSpace(name),
+		Qualifier: strings.TrimSpace(qualifier),
+	}
+}
+
+// prepositions introduce a place: "in New York", "for Cluj", "la Brașov".
+var prepositions = map[string]bool{
+	"in": true, "at": true, "for": true, "near": true, "around": true,
+	"în": true, "la": true, "din": true,
+}
+
+// connectors may appear in lowercase inside a name, as in "Rio de Janeiro"
+// or "Frankfurt am Main".
+var connectors = map[string]bool{
+	"de": true, "del": true, "da": true, "do": true, "di": true, "am": true,
+	"an": true, "upon": true, "on": true, "sur": true, "la": true, "le": true,
+	"el": true, "of": true,
+}
+
+// stopWords end a place name.
+var stopWords = map[string]bool{
+	"today": true, "tonight": true, "tomorrow": true, "now": true, "right": true,
+	"this": true, "next": true, "on": true, "over": true, "during": true,
+	"at": true, "in": true, "for": true, "and": true, "or": true, "with": true,
+	"if": true, "when": true, "please": true, "is": true, "will": true,
+	"be": true, "going": true, "like": true, "weather": true, "forecast": true,
+	"morning": true, "afternoon": true, "evening": true, "night": true,
+	"weekend": true, "week": true, "monday": true, "tuesday": true,
+	"wednesday": true, "thursday": true, "friday": true, "saturday": true,
+	"sunday": true, "later": true, "outside": true,
+}
+
+// notPlaces follow a preposition without being a place, as in "in
+// general" or "at the moment".
+var notPlaces = map[string]bool{
+	"general": true, "the": true, "a": true, "an": true, "my": true,
+	"your": true, "it": true, "that": true, "there": true, "case": true,
+	"fact": true, "order": true, "time": true, "moment": true, "degrees": true,
+	"celsius": true, "fahrenheit": true, "total": true, "detail": true,
+}
+
+var questionWords = map[string]bool{
+	"what": true, "what's": true, "whats": true, "how": true, "how's": true,
+	"is": true, "will": true, "does": true, "do": true, "should": true,
+	"tell": true, "give": true, "can": true,
+}
+
+type token struct {
+	word  string
+	lower string
+	// stop is set when punctuation ends the phrase after this word.
+	stop  bool
+	comma bool
+}
+
+func tokenize(text string) []token {
+	var tokens []token
+	for _, field := range strings.Fields(text) {
+		word := strings.TrimRightFunc(field, unicode.IsPunct)
+		trailing := field[len(word):]
+		word = strings.TrimLeftFunc(word, func(r rune) bool { return unicode.IsPunct(r) && r != '\'' })
+		if word == "" {
+			continue
+		}
+
+		tokens = append(tokens, token{
+			word:  word,
+			lower: strings.ToLower(word),
+			stop:  strings.ContainsAny(trailing, ".?!;:"),
+			comma: strings.Contains(trailing, ","),
+		})
+	}
+	return tokens
+}
+
+func capitalized(word string) bool {
+	for _, r := range word {
+		return unicode.IsUpper(r)
+	}
+	return false
+}
+
+// Extract finds the place a question is about. Transcripts capitalize
+// place names, which is what separates "in New York" from "in general";
+// typed questions in lowercase fall back to reading up to the next word
+// that cannot be part of a name.
+func Extract(text string) (Query, bool) {
+	tokens := tokenize(text)
+	lowercase := text == strings.ToLower(text)
+
+	for i, t := range tokens {
+		if !prepositions[t.lower] || t.stop || t.comma {
+			continue
+		}
+
+		if q, ok := readPlace(tokens[i+1:], lowercase); ok {
+			return q, true
+		}
+	}
+
+	// "Cluj weather tomorrow" or "New York forecast".
+	for i, t := range tokens {
+		if t.lower != "weather" && t.lower != "forecast" {
+			continue
+		}
+
+		start := i
+		for start > 0 && capitalized(tokens[start-1].word) && !tokens[start-1].stop && !tokens[start-1].comma {
+			start--
+		}
+		if start < i && !questionWords[tokens[start].lower] {
+			return Query{Name: joinWords(tokens[start:i])}, true
+		}
+	}
+
+	for _, t := range tokens {
+		if t.lower == "here" {
+			return Query{Here: true}, true
+		}
+	}
+
+	return Query{}, false
+}
+
+func readPlace(tokens []token, lowercase bool) (Query, bool) {
+	if len(tokens) == 0 {
+		return Query{}, false
+	}
+
+	switch first := tokens[0].lower; {
+	case first == "here":
+		return Query{Here: true}, true
+	case first == "home" || (first == "my" && len(tokens) > 1 && tokens[1].lower == "home"):
+		return Query{Here: true}, true
+	case notPlaces[first] && !capitalized(tokens[0].word):
+		return Query{}, false
+	}
+
+	var name, qualifier []token
+	part := &name
+
+	for i, t := range tokens {
+		if stopWords[t.lower] && !(connectors[t.lower] && i+1 < len(tokens) && capitalized(tokens[i+1].word) && !stopWords[tokens[i+1].lower]) {
+			break
+		}
+
+		if !lowercase && !capitalized(t.word) {
+			isConnector := connectors[t.lower] && len(*part) > 0 && !t.stop && !t.comma &&
+				i+1 < len(tokens) && capitalized(tokens[i+1].word)
+			if !isConnector {
+				break
+			}
+		}
+
+		*part = append(*part, t)
+
+		if t.stop {
+			break
+		}
+		if t.comma {
+			if part == &qualifier {
+				break
+			}
+			part = &qualifier
+		}
+	}
+
+	if len(name) == 0 {
+		return Query{}, false
+	}
+
+	return Query{Name: joinWords(name), Qualifier: joinWords(qualifier)}, true
+}
+
+func joinWords(tokens []token) string {
+	words := make([]string, len(tokens))
+	for i, t := range tokens {
+		words[i] = t.word
+	}
+	return strings.Join(words, " ")
+}
+
+// fold lowercases text and strips diacritics, so "Brașov" matches
+// "Brasov" and "São Paulo" matches "Sao Paulo".
+func fold(text string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, text)
+	if err != nil {
+		folded = text
+	}
+	return strings.ToLower(strings.TrimSpace(folded))
+}
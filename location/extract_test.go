@@ -0,0 +1,50 @@
+package location_test
+
+import (
+	"KevinGo/location"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		text string
+		want location.Query
+		ok   bool
+	}{
+		{"What's the weather in New York tomorrow?", location.Query{Name: "New York"}, true},
+		{"Is it raining in Paris, Texas?", location.Query{Name: "Paris", Qualifier: "Texas"}, true},
+		{"What's the weather like in Rio de Janeiro?", location.Query{Name: "Rio de Janeiro"}, true},
+		{"weather in rio de janeiro tomorrow", location.Query{Name: "rio de janeiro"}, true},
+		{"How cold is it in Brașov tonight?", location.Query{Name: "Brașov"}, true},
+		{"Cluj weather tomorrow", location.Query{Name: "Cluj"}, true},
+		{"Should I take an umbrella at home?", location.Query{Here: true}, true},
+		{"What's the weather like here?", location.Query{Here: true}, true},
+		{"How is the weather in general?", location.Query{}, false},
+		{"is it cold in the morning", location.Query{}, false},
+		{"What's the weather tomorrow?", location.Query{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := location.Extract(tt.text)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Extract(%q) = %+v, %v; want %+v, %v", tt.text, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		want location.Query
+	}{
+		{"Cluj-Napoca", location.Query{Name: "Cluj-Napoca"}},
+		{"Paris, Texas", location.Query{Name: "Paris", Qualifier: "Texas"}},
+		{"  Springfield ,Illinois ", location.Query{Name: "Springfield", Qualifier: "Illinois"}},
+	}
+
+	for _, tt := range tests {
+		if got := location.Parse(tt.name); got != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
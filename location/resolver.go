@@ -0,0 +1,210 @@
+package location
+
+import (
+	"KevinGo/weatherapi"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Geocoder turns a place name into candidate places, most relevant first.
+// weatherapi.OpenMeteo is one.
+type Geocoder interface {
+	Geocode(ctx context.Context, name string, count int) ([]weatherapi.Place, error)
+}
+
+// candidates is how many matches are compared when a name is ambiguous.
+const candidates = 10
+
+// Result is either a place or a question to ask the user, when the place
+// is missing or ambiguous. Candidates lists the places the question is
+// about.
+type Result struct {
+	Place      *weatherapi.Place
+	Question   string
+	Candidates []weatherapi.Place
+}
+
+// Resolver finds the place a question is about.
+type Resolver struct {
+	Geocoder Geocoder
+	// Home is used when the question names no place, such as "Cluj-Napoca,
+	// Romania". When empty, the user is asked instead.
+	Home string
+}
+
+// Resolve geocodes q, or the home location when q is empty.
+func (r *Resolver) Resolve(ctx context.Context, q Query) (Result, error) {
+	if q.IsZero() || q.Here {
+		if r.Home == "" {
+			return Result{Question: "Which city do you mean?"}, nil
+		}
+		q = Parse(r.Home)
+	}
+
+	places, err := r.Geocoder.Geocode(ctx, q.Name, candidates)
+	if err != nil {
+		return Result{}, err
+	}
+
+	// The geocoder may not know the spelling with diacritics, or without
+	// them, so try the folded name too.
+	if folded := fold(q.Name); len(places) == 0 && folded != strings.ToLower(q.Name) {
+		if places, err = r.Geocoder.Geocode(ctx, folded, candidates); err != nil {
+			return Result{}, err
+		}
+	}
+
+	places = preferSettlements(places)
+	if len(places) == 0 {
+		return Result{Question: fmt.Sprintf("I couldn't find a place called %s. Which city do you mean?", q)}, nil
+	}
+
+	if q.Qualifier != "" {
+		places = filterByQualifier(places, q.Qualifier)
+		if len(places) == 0 {
+			return Result{Question: fmt.Sprintf("I couldn't find %s. Which city do you mean?", q)}, nil
+		}
+		return Result{Place: &places[0]}, nil
+	}
+
+	if rivals := ambiguous(places); len(rivals) > 1 {
+		return Result{
+			Question:   fmt.Sprintf("There are several places called %s. Which one do you mean: %s?", places[0].Name, listPlaces(rivals)),
+			Candidates: rivals,
+		}, nil
+	}
+
+	return Result{Place: &places[0]}, nil
+}
+
+// ordinals let the user answer a follow-up with "the second one".
+var ordinals = map[string]int{
+	"first": 0, "1st": 0, "second": 1, "2nd": 1, "third": 2, "3rd": 2,
+	"fourth": 3, "4th": 3, "fifth": 4, "5th": 4,
+}
+
+// Choose interprets the answer to a follow-up question. It picks one of
+// candidates by region, country or position, or resolves the answer as a
+// new place.
+func (r *Resolver) Choose(ctx context.Context, answer string, candidates []weatherapi.Place) (Result, error) {
+	folded := fold(answer)
+
+	if len(candidates) > 0 {
+		var matches []weatherapi.Place
+		for _, place := range candidates {
+			if mentions(folded, place.Admin1) || mentions(folded, place.Country) ||
+				(place.CountryCode != "" && containsWord(folded, fold(place.CountryCode))) {
+				matches = append(matches, place)
+			}
+		}
+		if len(matches) == 1 {
+			return Result{Place: &matches[0]}, nil
+		}
+
+		// The first ordinal in the answer counts, as in "the second, not
+		// the first".
+		for _, word := range strings.FieldsFunc(folded, func(r rune) bool { return r < 0x80 && !isLetter(byte(r)) }) {
+			if index, ok := ordinals[word]; ok && index < len(candidates) {
+				return Result{Place: &candidates[index]}, nil
+			}
+		}
+	}
+
+	q, ok := Extract(answer)
+	if !ok {
+		q = Parse(strings.Trim(answer, " .!?"))
+	}
+	return r.Resolve(ctx, q)
+}
+
+// ambiguous returns the places sharing the top match's name that are big
+// enough to be what the user meant. A city far larger than its namesakes,
+// like Paris in France, is not ambiguous.
+func ambiguous(places []weatherapi.Place) []weatherapi.Place {
+	top := places[0]
+	var rivals []weatherapi.Place
+
+	for _, place := range places {
+		if fold(place.Name) != fold(top.Name) {
+			continue
+		}
+		if place.Population*5 < top.Population {
+			continue
+		}
+		rivals = append(rivals, place)
+	}
+
+	if len(rivals) > 4 {
+		rivals = rivals[:4]
+	}
+	return rivals
+}
+
+// preferSettlements drops regions such as the state of New York when a
+// city of the same name was found too.
+func preferSettlements(places []weatherapi.Place) []weatherapi.Place {
+	var settlements []weatherapi.Place
+	for _, place := range places {
+		if !strings.HasPrefix(place.FeatureCode, "ADM") {
+			settlements = append(settlements, place)
+		}
+	}
+
+	if len(settlements) == 0 {
+		return places
+	}
+	return settlements
+}
+
+func filterByQualifier(places []weatherapi.Place, qualifier string) []weatherapi.Place {
+	folded := fold(qualifier)
+
+	var matches []weatherapi.Place
+	for _, place := range places {
+		if mentions(folded, place.Admin1) || mentions(folded, place.Country) || folded == fold(place.CountryCode) {
+			matches = append(matches, place)
+		}
+	}
+	return matches
+}
+
+// mentions reports whether the folded text contains the folded name as
+// whole words.
+func mentions(text, name string) bool {
+	name = fold(name)
+	return name != "" && containsWord(text, name)
+}
+
+func containsWord(text, word string) bool {
+	for i := strings.Index(text, word); i >= 0; {
+		end := i + len(word)
+		before := i == 0 || !isLetter(text[i-1])
+		after := end == len(text) || !isLetter(text[end])
+		if before && after {
+			return true
+		}
+
+		next := strings.Index(text[i+1:], word)
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return false
+}
+
+func isLetter(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= '0' && b <= '9' || b >= 0x80
+}
+
+func listPlaces(places []weatherapi.Place) string {
+	names := make([]string, len(places))
+	for i, place := range places {
+		names[i] = place.String()
+	}
+	if len(names) == 1 {
+		return names[0]
+	}
+	return strings.Join(names[:len(names)-1], "; ") + " or " + names[len(names)-1]
+}
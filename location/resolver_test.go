@@ -0,0 +1,127 @@
+package location_test
+
+import (
+	"KevinGo/location"
+	"KevinGo/weatherapi/weathertest"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func newResolver(t *testing.T, home string) (*location.Resolver, *weathertest.Server) {
+	t.Helper()
+	srv := weathertest.NewServer()
+	t.Cleanup(srv.Close)
+	return &location.Resolver{Geocoder: srv.OpenMeteo(), Home: home}, srv
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name  string
+		query location.Query
+		home  string
+		place string
+		// question is the start of the question asked instead.
+		question string
+	}{
+		{name: "far larger namesake wins", query: location.Query{Name: "Paris"}, place: "Paris, Île-de-France, France"},
+		{name: "qualifier", query: location.Parse("Paris, Texas"), place: "Paris, Texas, United States"},
+		{name: "qualifier by country", query: location.Parse("Paris, Canada"), place: "Paris, Ontario, Canada"},
+		{name: "city before region", query: location.Query{Name: "New York"}, place: "New York, United States"},
+		{name: "diacritics", query: location.Query{Name: "Brașov"}, place: "Brașov, Romania"},
+		{name: "home", query: location.Query{Here: true}, home: "Cluj-Napoca, Romania", place: "Cluj-Napoca, Cluj, Romania"},
+		{name: "no home", query: location.Query{}, question: "Which city do you mean?"},
+		{name: "unknown", query: location.Query{Name: "Atlantis"}, question: "I couldn't find a place called Atlantis."},
+		{name: "unknown qualifier", query: location.Parse("Paris, Narnia"), question: "I couldn't find Paris, Narnia."},
+		{name: "ambiguous", query: location.Query{Name: "Springfield"}, question: "There are several places called Springfield."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := newResolver(t, tt.home)
+			result, err := r.Resolve(context.Background(), tt.query)
+			if err != nil {
+				t.Fatalf("Resolve: %v", err)
+			}
+
+			if tt.question != "" {
+				if result.Place != nil || !strings.HasPrefix(result.Question, strings.TrimSuffix(tt.question, ".")) {
+					t.Errorf("result = %+v, want the question %q", result, tt.question)
+				}
+				return
+			}
+			if result.Place == nil {
+				t.Fatalf("result = %+v, want %s", result, tt.place)
+			}
+			if got := result.Place.String(); got != tt.place {
+				t.Errorf("place = %s, want %s", got, tt.place)
+			}
+		})
+	}
+}
+
+func TestResolveFoldsDiacritics(t *testing.T) {
+	r, srv := newResolver(t, "")
+	if _, err := r.Resolve(context.Background(), location.Query{Name: "Brașov"}); err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if got := srv.Requests(); len(got) != 2 || !strings.Contains(got[1], "name=brasov") {
+		t.Errorf("requests = %q, want a second search without diacritics", got)
+	}
+}
+
+// Springfield is ambiguous; the follow-up lists the biggest four.
+func TestResolveAmbiguous(t *testing.T) {
+	r, _ := newResolver(t, "")
+	result, err := r.Resolve(context.Background(), location.Query{Name: "Springfield"})
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+
+	var regions []string
+	for _, place := range result.Candidates {
+		regions = append(regions, place.Admin1)
+	}
+	if want := []string{"Missouri", "Massachusetts", "Illinois", "Oregon"}; !slices.Equal(regions, want) {
+		t.Errorf("candidates = %q, want %q", regions, want)
+	}
+	if !strings.HasSuffix(result.Question, "Springfield, Oregon, United States?") {
+		t.Errorf("question = %q, want the candidates listed", result.Question)
+	}
+}
+
+func TestChoose(t *testing.T) {
+	tests := []struct {
+		answer string
+		want   string
+	}{
+		{"The one in Illinois.", "Springfield, Illinois, United States"},
+		{"massachusetts", "Springfield, Massachusetts, United States"},
+		{"The second one", "Springfield, Massachusetts, United States"},
+		{"the 4th", "Springfield, Oregon, United States"},
+		{"The third, not the first", "Springfield, Illinois, United States"},
+		{"Paris", "Paris, Île-de-France, France"},
+		{"in Brașov", "Brașov, Romania"},
+	}
+
+	r, _ := newResolver(t, "")
+	ambiguous, err := r.Resolve(context.Background(), location.Query{Name: "Springfield"})
+	if err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+
+	for _, tt := range tests {
+		result, err := r.Choose(context.Background(), tt.answer, ambiguous.Candidates)
+		if err != nil {
+			t.Fatalf("Choose(%q): %v", tt.answer, err)
+		}
+		if result.Place == nil {
+			t.Errorf("Choose(%q) = %+v, want %s", tt.answer, result, tt.want)
+			continue
+		}
+		if got := result.Place.String(); got != tt.want {
+			t.Errorf("Choose(%q) = %s, want %s", tt.answer, got, tt.want)
+		}
+	}
+}
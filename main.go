@@ -1,50 +1,115 @@
 package main
 
 import (
+	"KevinGo/config"
+	"KevinGo/conversation"
 	"KevinGo/enhancedcontext"
+	"KevinGo/intent"
 	"KevinGo/ollama"
 	"KevinGo/poll"
-	"bufio"
+	"KevinGo/speaker"
+	"KevinGo/stt"
+	"KevinGo/tts"
+	"KevinGo/wakeword"
+	"KevinGo/weatherapi"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/exec"
 	"os/signal"
 	"path/filepath"
-	"runtime"
-	"strings"
 	"syscall"
-	"time"
 
-	"github.com/go-audio/audio"
-	"github.com/go-audio/wav"
 	"github.com/gordonklaus/portaudio"
-	htgotts "github.com/hegedustibor/htgo-tts"
-	"github.com/hegedustibor/htgo-tts/voices"
 )
 
+var cfg *config.Config
+
+// status receives progress messages of the audio pipeline. Text mode moves
+// it to stderr when stdout is piped.
+var status io.Writer = os.Stdout
+
 func main() {
-	if _, err := os.Stat("assets"); os.IsNotExist(err) {
-		os.Mkdir("assets", 0755)
+	var err error
+	cfg, err = config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(2)
 	}
+	applyConfig()
 
-	fmt.Println("🔍 Checking if Ollama is available...")
-	if err := ollama.CheckOllamaStatus(); err != nil {
+	switch cfg.Command {
+	case "devices":
+		err = runDevices(os.Stdout)
+	case "mic-test":
+		err = runMicTest()
+	}
+	if err != nil {
 		fmt.Printf("❌ %v\n", err)
-		fmt.Println("\n📋 To install and run Ollama:")
-		fmt.Println("1. Install: brew install ollama (or https://ollama.ai/download)")
-		fmt.Println("2. Run in terminal: ollama pull llama3.2")
-		fmt.Println("3. Start server: ollama serve")
-		fmt.Println("\n🛑 Application stopping...")
+		os.Exit(1)
+	}
+	if cfg.Command != "" {
+		return
+	}
+
+	if _, err := os.Stat(cfg.Audio.AssetsDir); os.IsNotExist(err) {
+		os.MkdirAll(cfg.Audio.AssetsDir, 0755)
+	}
+
+	if cfg.Text.Enabled {
+		runTextMode()
 		return
 	}
-	fmt.Println("✅ Ollama is functional!")
+
+	printWarnings(os.Stdout)
+	if !checkOllama(os.Stdout) {
+		return
+	}
+
+	transcriber, err := newTranscriber()
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+	fmt.Printf("📝 Speech-to-text backend: %s\n", transcriber.Name())
+
+	streamer := newStreamer()
+	if streamer != nil {
+		fmt.Println("📡 Streaming transcription: what you say is shown as you say it")
+	}
+
+	synthesizers, err := newSynthesizers()
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+
+	var wake *wakeword.Detector
+	if cfg.Wake.Enabled {
+		wake, err = newWakeDetector()
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+		fmt.Printf("👂 Wake word mode: say \"%s\" to start a request\n", cfg.Wake.Phrase)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 
 	go func() {
 		<-c
+		cancel()
 		fmt.Println("\n\n👋 Application closing gracefully...")
 		portaudio.Terminate()
 		os.Exit(0)
@@ -53,301 +118,225 @@ func main() {
 	portaudio.Initialize()
 	defer portaudio.Terminate()
 
+	if err := selectDevices(os.Stdout, true); err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+
 	fmt.Println("🎙️ Continuous conversation mode activated!")
 	fmt.Println("📢 Press Control+C to exit the application")
 
+	session := newSession(os.Stdout)
+	enhancedcontext.OnTimer = func(announcement string) {
+		fmt.Printf("\n⏰ %s\n", announcement)
+		speak(synthesizers, announcement)
+	}
 	conversationCount := 0
+	var interrupted *interruption
 
 	for {
 		conversationCount++
 		fmt.Printf("\n🗣️ Conversation #%d\n", conversationCount)
-		fmt.Println("🎤 Press Enter to start recording...")
-		bufio.NewReader(os.Stdin).ReadBytes('\n')
 
-		fileWav := "assets/audio.wav"
-		fileM4a := "assets/audio.m4a"
+		transcribedText, err := hear(transcriber, streamer, wake, interrupted)
+		interrupted = nil
 
-		in := make([]int16, 64)
-		stream, err := portaudio.OpenDefaultStream(1, 0, 44100, len(in), in)
-		if err != nil {
-			log.Printf("❌ PortAudio error: %v", err)
+		if errors.Is(err, errNothingHeard) {
+			fmt.Println("💤 No request heard, going back to sleep.")
 			continue
-		}
-
-		f, err := os.Create(fileWav)
-		if err != nil {
-			log.Printf("❌ File creation error: %v", err)
-			stream.Close()
+		} else if err != nil {
+			log.Printf("❌ %v", err)
+			message := explain(err)
+			fmt.Printf("🔄 %s\n", message)
+			speak(synthesizers, message)
 			continue
 		}
-		enc := wav.NewEncoder(f, 44100, 16, 1, 1)
-
-		fmt.Println("🎙 Recording... Press Enter to stop.")
-		stream.Start()
-		stopChan := make(chan bool)
-
-		go func() {
-			for {
-				select {
-				case <-stopChan:
-					return
-				default:
-					stream.Read()
-					buf := &audio.IntBuffer{
-						Data:   intSlice(in),
-						Format: &audio.Format{SampleRate: 44100, NumChannels: 1},
-					}
-					enc.Write(buf)
-				}
-			}
-		}()
-
-		bufio.NewReader(os.Stdin).ReadBytes('\n')
-		stopChan <- true
 
-		stream.Stop()
-		stream.Close()
-		enc.Close()
-		f.Close()
-
-		cmd := exec.Command("ffmpeg", "-y", "-i", fileWav, "-c:a", "aac", fileM4a)
-		if err := cmd.Run(); err != nil {
-			log.Printf("❌ Conversion error: %v", err)
-			continue
-		}
-
-		os.Remove(fileWav)
-
-		fmt.Println("🔄 Transcribing audio...")
-		transcribedText := poll.StartPolling()
-
-		if transcribedText == "" {
-			fmt.Println("❌ Could not transcribe audio")
-			fmt.Println("🔄 Try again...")
-			continue
+		if wake != nil {
+			transcribedText = wake.Strip(transcribedText)
 		}
 
 		fmt.Printf("✅ Transcribed text: %s\n", transcribedText)
 
-		fmt.Println("🤖 Processing question with Ollama...")
-		fullContext := getKevinContext() + "\n\n" + enhancedcontext.GetSpecializedContext(transcribedText)
-		response, err := ollama.AskWithContext(transcribedText, fullContext)
+		turn := enhancedcontext.Prepare(ctx, transcribedText)
+		if turn.Answer == "" {
+			fmt.Println("🤖 Processing question with Ollama...")
+		}
+		fmt.Print("\n💬 Response: ")
+		interrupted, err = respond(ctx, session, synthesizers, transcribedText, turn)
 		if err != nil {
 			log.Printf("❌ Ollama error: %v", err)
 			fmt.Println("🔄 Try again...")
 			continue
 		}
-
-		fmt.Printf("\n💬 Response: %s\n", response)
-
-		fmt.Println("🎵 Generating audio...")
-		if err := generateTTSWithFallbacks(response); err != nil {
-			log.Printf("❌ Could not generate audio: %v", err)
-			fmt.Println("🔄 Ready for next question...")
+		if interrupted != nil {
 			continue
 		}
 
-		if err := playAudio(); err != nil {
-			log.Printf("❌ Audio playback error: %v", err)
-		}
-
 		fmt.Println("🔄 Ready for next question...")
 	}
 }
 
-func generateTTSWithFallbacks(response string) error {
-	cleanAudioFolder()
-
-	shortResponse := shortenResponse(response)
-	fmt.Printf("🔤 TTS text (%d characters): %s\n", len(shortResponse), shortResponse)
-
-	fallbacks := []struct {
-		name string
-		fn   func(string) error
-	}{
-		{"macOS say command", generateWithSayCommand},
-		{"htgo-tts short", func(text string) error { return generateWithHTGOTTS(text, true) }},
-		{"htgo-tts standard", func(text string) error { return generateWithHTGOTTS(text, false) }},
+func printWarnings(w io.Writer) {
+	for _, warning := range cfg.Warnings() {
+		fmt.Fprintf(w, "⚠️ %s\n", warning)
 	}
+}
 
-	for _, fallback := range fallbacks {
-		fmt.Printf("🔄 Trying %s...\n", fallback.name)
-		if err := fallback.fn(shortResponse); err != nil {
-			fmt.Printf("❌ %s failed: %v\n", fallback.name, err)
-			continue
-		}
-
-		if isValidAudioFile("assets/response.mp3") {
-			fmt.Printf("✅ Audio generated successfully using %s\n", fallback.name)
-			return nil
-		} else {
-			fmt.Printf("❌ %s generated invalid file\n", fallback.name)
-		}
+// checkOllama reports on w whether Ollama is reachable and explains how to
+// start it when it is not.
+func checkOllama(w io.Writer) bool {
+	fmt.Fprintln(w, "🔍 Checking if Ollama is available...")
+	err := ollama.CheckOllamaStatus()
+	if errors.Is(err, ollama.ErrModelMissing) {
+		fmt.Fprintf(w, "❌ %v\n", err)
+		fmt.Fprintln(w, "\n🛑 Application stopping...")
+		return false
 	}
-
-	return fmt.Errorf("all TTS methods failed")
+	if err != nil {
+		fmt.Fprintf(w, "❌ %v\n", err)
+		fmt.Fprintln(w, "\n📋 To install and run Ollama:")
+		fmt.Fprintln(w, "1. Install: brew install ollama (or https://ollama.ai/download)")
+		fmt.Fprintf(w, "2. Run in terminal: ollama pull %s\n", cfg.Ollama.Model)
+		fmt.Fprintln(w, "3. Start server: ollama serve")
+		fmt.Fprintln(w, "\n🛑 Application stopping...")
+		return false
+	}
+	fmt.Fprintln(w, "✅ Ollama is functional!")
+	return true
 }
 
-func generateWithSayCommand(text string) error {
-	if runtime.GOOS != "darwin" {
-		return fmt.Errorf("say command only available on macOS")
+// newSession starts a conversation, offering the skills to the model as
+// tools when it supports them.
+func newSession(w io.Writer) *conversation.Session {
+	session := conversation.NewSession(getKevinContext())
+	if cfg.Ollama.Options.NumCtx > 0 {
+		session.MaxTokens = cfg.Ollama.Options.NumCtx
 	}
-
-	if _, err := exec.LookPath("say"); err != nil {
-		return fmt.Errorf("say command not available")
+	if !cfg.Ollama.Tools {
+		return session
 	}
 
-	tempFile := "assets/temp_response.aiff"
-	finalFile := "assets/response.mp3"
-
-	cmd := exec.Command("say", "-v", "Samantha", "-r", "180", "-o", tempFile, text)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("say command error: %w", err)
+	supported, err := ollama.SupportsTools()
+	if err != nil {
+		fmt.Fprintf(w, "⚠️ Could not check whether %s supports tools: %v\n", cfg.Ollama.Model, err)
+		return session
 	}
-
-	if _, err := exec.LookPath("ffmpeg"); err == nil {
-		cmd = exec.Command("ffmpeg", "-y", "-i", tempFile, "-codec:a", "libmp3lame", "-b:a", "128k", finalFile)
-		if err := cmd.Run(); err != nil {
-			os.Remove(tempFile)
-			return fmt.Errorf("ffmpeg conversion error: %w", err)
-		}
-		os.Remove(tempFile)
-	} else {
-		return os.Rename(tempFile, finalFile)
+	if !supported {
+		fmt.Fprintf(w, "⚠️ %s does not support tools, so skill data is added to the prompt instead\n", cfg.Ollama.Model)
+		return session
 	}
 
-	return nil
+	session.Tools = enhancedcontext.Skills
+	session.MaxToolRounds = cfg.Ollama.MaxToolRounds
+	enhancedcontext.Skills.ToolCalling = true
+	fmt.Fprintln(w, "🧰 Skills are available to the model as tools")
+	return session
 }
 
-func generateWithHTGOTTS(text string, veryShort bool) error {
-	if veryShort && len(text) > 100 {
-		words := strings.Fields(text)
-		if len(words) > 15 {
-			text = strings.Join(words[:15], " ") + "."
-		}
+// applyConfig hands the loaded settings to the packages that still read
+// them from package-level variables.
+func applyConfig() {
+	if provider, err := weatherapi.NewProvider(cfg.Weather.Provider, cfg.Weather.APIKey); err == nil {
+		weatherapi.Provider = provider
 	}
-
-	speech := htgotts.Speech{
-		Folder:   "assets",
-		Language: voices.English,
+	enhancedcontext.Locations.Home = cfg.Weather.Home
+	client := ollama.NewClient(cfg.Ollama.URL, cfg.Ollama.Model)
+	client.Timeout = cfg.Ollama.Timeout
+	client.Retries = cfg.Ollama.Retries
+	client.HTTPClient = ollama.NewHTTPClient(cfg.Ollama.ConnectTimeout, cfg.Ollama.ResponseTimeout)
+	client.Options = ollama.Options{
+		Temperature: cfg.Ollama.Options.Temperature,
+		TopP:        cfg.Ollama.Options.TopP,
+		NumCtx:      cfg.Ollama.Options.NumCtx,
+		NumPredict:  cfg.Ollama.Options.NumPredict,
+		Stop:        cfg.Ollama.Options.Stop,
+		Seed:        cfg.Ollama.Options.Seed,
+		KeepAlive:   cfg.Ollama.Options.KeepAlive,
 	}
-
-	if err := speech.Speak(text); err != nil {
-		return fmt.Errorf("htgo-tts error: %w", err)
+	ollama.DefaultClient = client
+	if cfg.Intent.Enabled {
+		enhancedcontext.Skills.Classifier = &intent.Classifier{Timeout: cfg.Intent.Timeout}
+		enhancedcontext.Skills.MinConfidence = cfg.Intent.MinConfidence
 	}
-
-	time.Sleep(3 * time.Second)
-
-	return findAndRenameGeneratedFile()
 }
 
-func findAndRenameGeneratedFile() error {
-	files, err := filepath.Glob("assets/*.mp3")
+func newTranscriber() (stt.Transcriber, error) {
+	pollOptions := poll.DefaultOptions()
+	pollOptions.Interval = cfg.AssemblyAI.PollInterval
+	pollOptions.Timeout = cfg.AssemblyAI.PollTimeout
+
+	transcriber, err := stt.New(cfg.STT.Backend, stt.AssemblyAI{
+		APIKey: cfg.AssemblyAI.APIKey,
+		Format: cfg.STT.UploadFormat,
+		Poll:   pollOptions,
+	}, stt.Whisper{
+		Binary:   cfg.STT.Whisper.Binary,
+		Model:    cfg.STT.Whisper.Model,
+		Language: cfg.STT.Whisper.Language,
+		TempDir:  cfg.Audio.AssetsDir,
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	for _, file := range files {
-		if filepath.Base(file) != "response.mp3" {
-			if isValidAudioFile(file) {
-				return os.Rename(file, "assets/response.mp3")
-			} else {
-				os.Remove(file)
-			}
+	if whisper, ok := transcriber.(stt.Whisper); ok {
+		if err := whisper.Check(); err != nil {
+			return nil, fmt.Errorf("the whisper speech-to-text backend is not set up: %w", err)
 		}
 	}
-
-	return fmt.Errorf("no valid audio file found")
+	return transcriber, nil
 }
 
-func isValidAudioFile(filepath string) bool {
-	file, err := os.Open(filepath)
-	if err != nil {
-		return false
-	}
-	defer file.Close()
-
-	if info, err := file.Stat(); err != nil || info.Size() < 1000 {
-		return false
-	}
-
-	header := make([]byte, 100)
-	n, err := file.Read(header)
-	if err != nil || n < 10 {
-		return false
-	}
-
-	headerStr := string(header[:n])
-
-	if strings.Contains(headerStr, "<!DOCTYPE") ||
-		strings.Contains(headerStr, "<html") ||
-		strings.Contains(headerStr, "<HTML") {
-		return false
-	}
-
-	if len(header) >= 4 {
-		if header[0] == 0xFF && (header[1]&0xFE) == 0xFA {
-			return true
-		}
-		if string(header[:4]) == "FORM" {
-			return true
-		}
-		if string(header[:4]) == "RIFF" {
-			return true
+// newSynthesizers builds the TTS fallback chain in the configured order.
+func newSynthesizers() (tts.Chain, error) {
+	var chain tts.Chain
+
+	for _, name := range cfg.TTS.Backends {
+		switch name {
+		case "say":
+			chain = append(chain, tts.Say{Voice: cfg.TTS.Voice, Rate: cfg.TTS.Rate, TempDir: cfg.Audio.AssetsDir})
+		case "piper":
+			chain = append(chain, tts.Piper{Binary: cfg.TTS.Piper.Binary, Model: cfg.TTS.Piper.Model, Rate: cfg.TTS.Rate, TempDir: cfg.Audio.AssetsDir})
+		case "espeak":
+			chain = append(chain, tts.Espeak{Binary: cfg.TTS.Espeak.Binary, Voice: cfg.TTS.Espeak.Voice, Rate: cfg.TTS.Rate})
+		case "htgo":
+			chain = append(chain, tts.HTGO{Language: cfg.TTS.HTGO.Language, TempDir: cfg.Audio.AssetsDir})
+		default:
+			return nil, fmt.Errorf("unknown TTS backend %q", name)
 		}
 	}
 
-	return true
+	return chain, nil
 }
 
-func shortenResponse(response string) string {
-	if strings.Contains(strings.ToLower(response), "weather") ||
-		strings.Contains(strings.ToLower(response), "temperature") {
-
-		sentences := strings.Split(response, ". ")
-		if len(sentences) > 3 {
-			return strings.Join(sentences[:3], ". ") + "."
-		}
-	}
-
-	if len(response) > 250 {
-		words := strings.Fields(response)
-		result := ""
-		for _, word := range words {
-			if len(result)+len(word)+1 > 147 {
-				break
-			}
-			if result != "" {
-				result += " "
+// newSpeaker speaks a response sentence by sentence while it streams in.
+func newSpeaker(ctx context.Context, synthesizers tts.Chain) *speaker.Speaker {
+	return speaker.New(ctx, speaker.Options{
+		Synthesizers: synthesizers,
+		OnAttempt: func(name string, err error) {
+			if err != nil {
+				fmt.Fprintf(status, "\n❌ %s failed: %v\n", name, err)
 			}
-			result += word
-		}
-		return result + "..."
-	}
-
-	return response
+		},
+		Fallback: playWithExternalPlayer,
+	})
 }
 
 func cleanAudioFolder() {
-	files, _ := filepath.Glob("assets/*.mp3")
-	for _, file := range files {
-		os.Remove(file)
-	}
-	files, _ = filepath.Glob("assets/*.aiff")
+	files, _ := filepath.Glob(filepath.Join(cfg.Audio.AssetsDir, "response.*"))
 	for _, file := range files {
 		os.Remove(file)
 	}
 }
 
-func playAudio() error {
-	audioFile := "assets/response.mp3"
-
-	if _, err := os.Stat(audioFile); os.IsNotExist(err) {
-		return fmt.Errorf("file %s does not exist", audioFile)
-	}
-
-	if !isValidAudioFile(audioFile) {
-		return fmt.Errorf("audio file is not valid")
+// playWithExternalPlayer is used when the native player cannot decode a
+// clip or open a device. The clip is saved to the assets folder first.
+func playWithExternalPlayer(ctx context.Context, audio *tts.Audio) error {
+	cleanAudioFolder()
+	audioFile := filepath.Join(cfg.Audio.AssetsDir, "response."+audio.Format)
+	if err := os.WriteFile(audioFile, audio.Data, 0644); err != nil {
+		return fmt.Errorf("error saving audio: %w", err)
 	}
 
 	players := []struct {
@@ -355,20 +344,19 @@ func playAudio() error {
 		cmd  []string
 	}{
 		{"afplay", []string{"afplay", audioFile}},
-		{"ffplay", []string{"ffplay", "-nodisp", "-autoexit", audioFile}},
-		{"mpg123", []string{"mpg123", audioFile}},
-		{"open", []string{"open", audioFile}}, // macOS default
+		{"ffplay", []string{"ffplay", "-nodisp", "-autoexit", "-loglevel", "quiet", audioFile}},
+		{"mpg123", []string{"mpg123", "-q", audioFile}},
 	}
 
 	for _, player := range players {
 		if _, err := exec.LookPath(player.cmd[0]); err == nil {
-			fmt.Printf("🔊 Playing with %s...\n", player.name)
-			cmd := exec.Command(player.cmd[0], player.cmd[1:]...)
+			cmd := exec.CommandContext(ctx, player.cmd[0], player.cmd[1:]...)
 			if err := cmd.Run(); err == nil {
-				fmt.Printf("✅ Playback completed with %s\n", player.name)
 				return nil
+			} else if ctx.Err() != nil {
+				return ctx.Err()
 			} else {
-				fmt.Printf("❌ %s error: %v\n", player.name, err)
+				fmt.Fprintf(status, "❌ %s error: %v\n", player.name, err)
 			}
 		}
 	}
@@ -376,14 +364,6 @@ func playAudio() error {
 	return fmt.Errorf("no functional audio player found")
 }
 
-func intSlice(in []int16) []int {
-	out := make([]int, len(in))
-	for i, v := range in {
-		out[i] = int(v)
-	}
-	return out
-}
-
 func getKevinContext() string {
 	return `Your name is Kira. You are a helpful AI assistant. 
 
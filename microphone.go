@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gordonklaus/portaudio"
+)
+
+// microphone reads the configured input on its own goroutine, so audio keeps
+// flowing into frames while the caller is busy, for example while a wake
+// word clip is being transcribed.
+type microphone struct {
+	stream *portaudio.Stream
+	frames chan []int16
+	stop   chan struct{}
+	done   chan struct{}
+	err    error
+}
+
+func openMicrophone(framesPerBuffer int) (*microphone, error) {
+	in := make([]int16, framesPerBuffer)
+	stream, err := openInput(in)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := stream.Start(); err != nil {
+		stream.Close()
+		return nil, fmt.Errorf("PortAudio error: %w", err)
+	}
+
+	m := &microphone{
+		stream: stream,
+		frames: make(chan []int16, 2048),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	go func() {
+		defer close(m.done)
+		defer close(m.frames)
+
+		for {
+			select {
+			case <-m.stop:
+				return
+			default:
+			}
+
+			if err := stream.Read(); err != nil && err != portaudio.InputOverflowed {
+				m.err = fmt.Errorf("PortAudio error: %w", err)
+				return
+			}
+
+			frame := append([]int16(nil), in...)
+			select {
+			case m.frames <- frame:
+			case <-m.stop:
+				return
+			}
+		}
+	}()
+
+	return m, nil
+}
+
+// openInput opens a mono stream on audio.input_device whose reads fill in.
+func openInput(in []int16) (*portaudio.Stream, error) {
+	dev, err := findDevice(cfg.Audio.InputDevice, true)
+	if err != nil {
+		return nil, fmt.Errorf("PortAudio error: %w", err)
+	}
+
+	p := portaudio.HighLatencyParameters(dev, nil)
+	p.Input.Channels = 1
+	p.SampleRate = float64(cfg.Audio.SampleRate)
+	p.FramesPerBuffer = len(in)
+
+	stream, err := portaudio.OpenStream(p, in)
+	if err != nil {
+		return nil, fmt.Errorf("PortAudio error: %s at %d Hz: %w", dev.Name, cfg.Audio.SampleRate, err)
+	}
+	return stream, nil
+}
+
+// Err reports why the frames channel was closed, once it has been.
+func (m *microphone) Err() error {
+	return m.err
+}
+
+func (m *microphone) Close() {
+	close(m.stop)
+	<-m.done
+	m.stream.Stop()
+	m.stream.Close()
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"KevinGo/playback"
+	"KevinGo/vad"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/gordonklaus/portaudio"
+)
+
+// micTestLength is how long `kira mic-test` records.
+const micTestLength = 4 * time.Second
+
+// runMicTest shows the level of the microphone while it records a short
+// sample, compares it with the speech threshold and plays the sample back.
+func runMicTest() error {
+	if err := portaudio.Initialize(); err != nil {
+		return fmt.Errorf("PortAudio error: %w", err)
+	}
+	defer portaudio.Terminate()
+
+	dev, err := findDevice(cfg.Audio.InputDevice, true)
+	if err != nil {
+		return fmt.Errorf("audio.input_device: %w", err)
+	}
+	fmt.Printf("🎤 Microphone: %s (%s) at %d Hz\n", dev.Name, dev.HostApi.Name, cfg.Audio.SampleRate)
+	if err := selectDevices(status, false); err != nil {
+		return err
+	}
+
+	mic, err := openMicrophone(512)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("🗣️ Say something, recording for %v. The mark shows the speech threshold.\n", micTestLength)
+	total := int(micTestLength.Seconds() * float64(cfg.Audio.SampleRate))
+	var samples []int16
+	var peak float64
+
+	for frame := range mic.frames {
+		level := vad.RMS(frame)
+		peak = max(peak, level)
+		fmt.Printf("\r\033[K%s", levelMeter(level, cfg.VAD.Threshold))
+
+		samples = append(samples, frame...)
+		if len(samples) >= total {
+			break
+		}
+	}
+	mic.Close()
+	fmt.Println()
+	if err := mic.Err(); err != nil {
+		return err
+	}
+
+	switch {
+	case peak == 0:
+		fmt.Println("❌ The microphone only delivered silence. Check that it is not muted and that Kira may use it.")
+	case peak < cfg.VAD.Threshold:
+		fmt.Printf("⚠️ The loudest moment (%.0f) stayed below vad.threshold (%.0f), so Kira would not hear you. Speak closer, raise the input gain or lower vad.threshold.\n", peak, cfg.VAD.Threshold)
+	default:
+		fmt.Printf("✅ The loudest moment (%.0f) is above vad.threshold (%.0f).\n", peak, cfg.VAD.Threshold)
+	}
+
+	fmt.Println("▶️ Playing the sample back...")
+	data := make([]byte, len(samples)*2)
+	for i, sample := range samples {
+		binary.LittleEndian.PutUint16(data[i*2:], uint16(sample))
+	}
+	pcm := &playback.PCM{Data: data, SampleRate: cfg.Audio.SampleRate, Channels: 1}
+	if err := playback.Play(context.Background(), pcm, nil); err != nil {
+		return fmt.Errorf("could not play the sample: %w", err)
+	}
+	return nil
+}
+
+// levelMeter draws an RMS level from -60 to 0 dBFS as a bar, with the
+// threshold marked.
+func levelMeter(level, threshold float64) string {
+	const width = 40
+
+	column := func(v float64) int {
+		if v <= 0 {
+			return 0
+		}
+		db := 20 * math.Log10(v/math.MaxInt16)
+		return min(max(int(math.Round((db+60)/60*width)), 0), width)
+	}
+	filled, mark := column(level), column(threshold)
+
+	var bar strings.Builder
+	for i := 0; i < width; i++ {
+		switch {
+		case i == mark:
+			bar.WriteRune('|')
+		case i < filled:
+			bar.WriteRune('█')
+		default:
+			bar.WriteRune('·')
+		}
+	}
+
+	db := math.Inf(-1)
+	if level > 0 {
+		db = 20 * math.Log10(level/math.MaxInt16)
+	}
+	return fmt.Sprintf("🎚️ [%s] %6.1f dBFS", bar.String(), db)
+}
@@ -0,0 +1,148 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type ChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+	// ToolCalls are the tools an assistant message asks to run.
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	// ToolName is the tool whose output a "tool" message carries.
+	ToolName string `json:"tool_name,omitempty"`
+}
+
+type ChatRequest struct {
+	Model    string        `json:"model"`
+	Messages []ChatMessage `json:"messages"`
+	Stream   bool          `json:"stream"`
+	// Format is "json" to make the model reply with a JSON object.
+	Format    string   `json:"format,omitempty"`
+	Tools     []Tool   `json:"tools,omitempty"`
+	Options   *Options `json:"options,omitempty"`
+	KeepAlive string   `json:"keep_alive,omitempty"`
+}
+
+type ChatResponse struct {
+	Message ChatMessage `json:"message"`
+	Done    bool        `json:"done"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// ChatStream sends the message history to /api/chat and calls onChunk with
+// every partial token of the assistant reply. The model may call tools,
+// in which case the returned message holds the calls, which the caller
+// runs before asking again with their results. When ctx is cancelled, the
+// part of the reply received so far is returned with the context's error.
+// overrides replace fields of the client's Options for this request only.
+func (c *Client) ChatStream(ctx context.Context, messages []ChatMessage, tools []Tool, onChunk func(string), overrides ...Options) (ChatMessage, error) {
+	options, keepAlive := c.requestOptions(overrides)
+	requestBody := ChatRequest{
+		Model:     c.Model,
+		Messages:  messages,
+		Stream:    true,
+		Tools:     tools,
+		Options:   options,
+		KeepAlive: keepAlive,
+	}
+
+	res, err := c.postJSON(ctx, "/api/chat", requestBody)
+	if err != nil {
+		return ChatMessage{}, err
+	}
+	defer res.Body.Close()
+
+	reply := ChatMessage{Role: "assistant"}
+	var full strings.Builder
+	decoder := json.NewDecoder(res.Body)
+
+	for {
+		var chunk ChatResponse
+		if err := decoder.Decode(&chunk); err != nil {
+			reply.Content = full.String()
+			if ctx.Err() != nil {
+				return reply, contextError(ctx)
+			}
+			if err == io.EOF {
+				return reply, fmt.Errorf("stream ended before completion")
+			}
+			return reply, fmt.Errorf("JSON parse error: %v", err)
+		}
+
+		if chunk.Error != "" {
+			reply.Content = full.String()
+			return reply, fmt.Errorf("Ollama error: %s", chunk.Error)
+		}
+
+		if chunk.Message.Content != "" {
+			full.WriteString(chunk.Message.Content)
+			if onChunk != nil {
+				onChunk(chunk.Message.Content)
+			}
+		}
+		reply.ToolCalls = append(reply.ToolCalls, chunk.Message.ToolCalls...)
+
+		if chunk.Done {
+			reply.Content = full.String()
+			return reply, nil
+		}
+	}
+}
+
+// ChatJSON asks for a reply in JSON, using Ollama's format option, and
+// decodes it into v.
+func (c *Client) ChatJSON(ctx context.Context, messages []ChatMessage, v interface{}, overrides ...Options) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	options, keepAlive := c.requestOptions(overrides)
+	requestBody := ChatRequest{
+		Model:     c.Model,
+		Messages:  messages,
+		Stream:    false,
+		Format:    "json",
+		Options:   options,
+		KeepAlive: keepAlive,
+	}
+
+	res, err := c.postJSON(ctx, "/api/chat", requestBody)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	var response ChatResponse
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		if ctx.Err() != nil {
+			return contextError(ctx)
+		}
+		return fmt.Errorf("JSON parse error: %v", err)
+	}
+	if response.Error != "" {
+		return fmt.Errorf("Ollama error: %s", response.Error)
+	}
+
+	if err := json.Unmarshal([]byte(response.Message.Content), v); err != nil {
+		return fmt.Errorf("model did not reply with valid JSON: %v", err)
+	}
+	return nil
+}
+
+// ChatStream, ChatStreamTools and ChatJSON use DefaultClient.
+func ChatStream(messages []ChatMessage, onChunk func(string), overrides ...Options) (string, error) {
+	reply, err := DefaultClient.ChatStream(context.Background(), messages, nil, onChunk, overrides...)
+	return reply.Content, err
+}
+
+func ChatStreamTools(ctx context.Context, messages []ChatMessage, tools []Tool, onChunk func(string), overrides ...Options) (ChatMessage, error) {
+	return DefaultClient.ChatStream(ctx, messages, tools, onChunk, overrides...)
+}
+
+func ChatJSON(ctx context.Context, messages []ChatMessage, v interface{}, overrides ...Options) error {
+	return DefaultClient.ChatJSON(ctx, messages, v, overrides...)
+}
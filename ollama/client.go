@@ -0,0 +1,214 @@
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrModelMissing is returned by CheckStatus when Ollama runs but the
+// model has not been pulled.
+var ErrModelMissing = errors.New("model is not pulled")
+
+// StatusError is returned when Ollama answers with a status other than
+// 200 OK.
+type StatusError struct {
+	Code    int
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("Ollama error %d: %s", e.Code, e.Message)
+}
+
+// Client talks to one Ollama server. Its fields may be changed before the
+// first request.
+type Client struct {
+	BaseURL string
+	Model   string
+	// Options are sent with every request; each call can override them.
+	Options Options
+	// Timeout bounds calls that return one answer, such as status checks
+	// and JSON chats. Streamed answers may take as long as they need once
+	// they have started; HTTPClient bounds how long they take to start.
+	Timeout time.Duration
+	// Retries is how many times a request is repeated after a connection
+	// error or a 5xx status, waiting Backoff, then twice as long, and so on.
+	Retries    int
+	Backoff    time.Duration
+	HTTPClient *http.Client
+}
+
+// DefaultClient is used by the package-level functions. It is set from
+// the configuration at startup.
+var DefaultClient = NewClient("http://localhost:11434", "llama3.2")
+
+func NewClient(baseURL, model string) *Client {
+	return &Client{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		Model:      model,
+		Timeout:    30 * time.Second,
+		Retries:    2,
+		Backoff:    500 * time.Millisecond,
+		HTTPClient: NewHTTPClient(5*time.Second, 2*time.Minute),
+	}
+}
+
+// NewHTTPClient gives up on connecting after connectTimeout and on an
+// answer that has not started after responseTimeout, which includes the
+// time Ollama takes to load the model.
+func NewHTTPClient(connectTimeout, responseTimeout time.Duration) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			DialContext:           (&net.Dialer{Timeout: connectTimeout}).DialContext,
+			ResponseHeaderTimeout: responseTimeout,
+			MaxIdleConnsPerHost:   4,
+			IdleConnTimeout:       90 * time.Second,
+		},
+	}
+}
+
+// CheckStatus verifies that Ollama is running and that the model has
+// been pulled.
+func (c *Client) CheckStatus(ctx context.Context) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	res, err := c.do(ctx, "GET", "/api/tags", nil)
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return fmt.Errorf("Ollama not responding correctly: %w", err)
+	}
+	if err != nil {
+		return fmt.Errorf("Ollama is not running. Start it with: ollama serve")
+	}
+	defer res.Body.Close()
+
+	var tags struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&tags); err != nil {
+		return fmt.Errorf("Ollama not responding correctly: %v", err)
+	}
+
+	for _, model := range tags.Models {
+		if sameModel(model.Name, c.Model) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s (pull it with: ollama pull %s)", ErrModelMissing, c.Model, c.Model)
+}
+
+// sameModel compares model names, where a missing tag means "latest".
+func sameModel(a, b string) bool {
+	withTag := func(name string) string {
+		if !strings.Contains(name, ":") {
+			return name + ":latest"
+		}
+		return name
+	}
+	return withTag(a) == withTag(b)
+}
+
+// requestOptions merges the overrides into the client's options and
+// splits the result into the options field and the keep_alive field of a
+// request.
+func (c *Client) requestOptions(overrides []Options) (*Options, string) {
+	o := c.Options
+	for _, override := range overrides {
+		o = o.Merge(override)
+	}
+
+	keepAlive := ""
+	if o.KeepAlive != nil {
+		keepAlive = o.KeepAlive.String()
+	}
+	if o.isZero() {
+		return nil, keepAlive
+	}
+	return &o, keepAlive
+}
+
+func (c *Client) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.Timeout)
+}
+
+func (c *Client) postJSON(ctx context.Context, path string, requestBody interface{}) (*http.Response, error) {
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("JSON error: %v", err)
+	}
+	return c.do(ctx, "POST", path, jsonData)
+}
+
+// do sends the request, retrying connection errors and 5xx statuses, and
+// returns the response once it has a 200 status.
+func (c *Client) do(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	for attempt := 0; ; attempt++ {
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, reader)
+		if err != nil {
+			return nil, fmt.Errorf("request error: %v", err)
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		res, err := httpClient.Do(req)
+		switch {
+		case err != nil && ctx.Err() != nil:
+			return nil, contextError(ctx)
+		case err != nil:
+			err = fmt.Errorf("Ollama not responding - check if 'ollama serve' is running: %v", err)
+		case res.StatusCode != http.StatusOK:
+			message, _ := io.ReadAll(res.Body)
+			res.Body.Close()
+			err = &StatusError{Code: res.StatusCode, Message: strings.TrimSpace(string(message))}
+			if res.StatusCode < 500 {
+				return nil, err
+			}
+		default:
+			return res, nil
+		}
+
+		if attempt >= c.Retries {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, contextError(ctx)
+		case <-time.After(c.Backoff << attempt):
+		}
+	}
+}
+
+// contextError explains why ctx ended. A cancelled request returns
+// context.Canceled as is, so callers can tell it apart from a failure.
+func contextError(ctx context.Context) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("Ollama did not answer in time: %w", ctx.Err())
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,117 @@
+package ollama_test
+
+import (
+	"KevinGo/conversation"
+	"KevinGo/ollama"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// recorder answers like Ollama and keeps the body of the last request.
+type recorder struct {
+	*httptest.Server
+	body map[string]any
+}
+
+func newRecorder(t *testing.T) *recorder {
+	r := &recorder{}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		data, _ := io.ReadAll(req.Body)
+		r.body = nil
+		if err := json.Unmarshal(data, &r.body); err != nil {
+			t.Errorf("request body %s: %v", data, err)
+		}
+		switch req.URL.Path {
+		case "/api/chat":
+			io.WriteString(w, `{"message": {"role": "assistant", "content": "Hi."}, "done": true}`+"\n")
+		default:
+			io.WriteString(w, `{"response": "Hi.", "done": true}`)
+		}
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *recorder) client() *ollama.Client {
+	c := ollama.NewClient(r.URL, "llama3.2")
+	keepAlive := 10 * time.Minute
+	c.Options = ollama.Options{
+		Temperature: ollama.Float(0.7),
+		NumCtx:      4096,
+		Stop:        []string{"User:"},
+		KeepAlive:   &keepAlive,
+	}
+	return c
+}
+
+func TestGenerateMergesOptions(t *testing.T) {
+	r := newRecorder(t)
+
+	_, err := r.client().Generate(context.Background(), "hello", ollama.Options{
+		Temperature: ollama.Float(0),
+		NumPredict:  64,
+	})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+
+	want := map[string]any{
+		"temperature": 0.0,
+		"num_ctx":     4096.0,
+		"num_predict": 64.0,
+		"stop":        []any{"User:"},
+	}
+	if got := r.body["options"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("options = %v, want %v", got, want)
+	}
+	if got := r.body["keep_alive"]; got != "10m0s" {
+		t.Errorf("keep_alive = %v, want 10m0s next to the options", got)
+	}
+}
+
+func TestSessionSendsSeed(t *testing.T) {
+	r := newRecorder(t)
+
+	session := conversation.NewSession("You are Kira.")
+	session.Client = r.client()
+	session.Options = ollama.Options{Seed: ollama.Int(42)}
+
+	if _, err := session.AskStream(context.Background(), "hello", "", nil); err != nil {
+		t.Fatalf("AskStream: %v", err)
+	}
+
+	options, _ := r.body["options"].(map[string]any)
+	if options["seed"] != 42.0 {
+		t.Errorf("seed = %v, want 42", options["seed"])
+	}
+	if options["temperature"] != 0.7 || options["num_ctx"] != 4096.0 {
+		t.Errorf("options = %v, want the client's defaults kept", options)
+	}
+	if _, ok := options["keep_alive"]; ok {
+		t.Errorf("options = %v, keep_alive belongs outside them", options)
+	}
+	if r.body["keep_alive"] != "10m0s" {
+		t.Errorf("keep_alive = %v, want 10m0s", r.body["keep_alive"])
+	}
+}
+
+// Without any options the field is left out, so Ollama uses the model's.
+func TestGenerateWithoutOptions(t *testing.T) {
+	r := newRecorder(t)
+
+	if _, err := ollama.NewClient(r.URL, "llama3.2").Generate(context.Background(), "hello"); err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if _, ok := r.body["options"]; ok {
+		t.Errorf("body = %v, want no options", r.body)
+	}
+	if _, ok := r.body["keep_alive"]; ok {
+		t.Errorf("body = %v, want no keep_alive", r.body)
+	}
+}
@@ -1,59 +1,53 @@
 package ollama
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
+	"io"
+	"strings"
 )
 
 type OllamaRequest struct {
-	Model  string `json:"model"`
-	Prompt string `json:"prompt"`
-	Stream bool   `json:"stream"`
+	Model     string   `json:"model"`
+	Prompt    string   `json:"prompt"`
+	Stream    bool     `json:"stream"`
+	Options   *Options `json:"options,omitempty"`
+	KeepAlive string   `json:"keep_alive,omitempty"`
 }
 
 type OllamaResponse struct {
 	Response string `json:"response"`
 	Done     bool   `json:"done"`
+	Error    string `json:"error,omitempty"`
 }
 
-func AskQuestion(question string) (string, error) {
-	const OLLAMA_URL = "http://localhost:11434/api/generate"
+// Generate sends the prompt to /api/generate. overrides replace fields of
+// the client's Options for this request only.
+func (c *Client) Generate(ctx context.Context, prompt string, overrides ...Options) (string, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
 
+	options, keepAlive := c.requestOptions(overrides)
 	requestBody := OllamaRequest{
-		Model:  "llama3.2",
-		Prompt: question,
-		Stream: false,
+		Model:     c.Model,
+		Prompt:    prompt,
+		Stream:    false,
+		Options:   options,
+		KeepAlive: keepAlive,
 	}
 
-	jsonData, err := json.Marshal(requestBody)
+	res, err := c.postJSON(ctx, "/api/generate", requestBody)
 	if err != nil {
-		return "", fmt.Errorf("JSON error: %v", err)
-	}
-
-	client := &http.Client{}
-	req, err := http.NewRequest("POST", OLLAMA_URL, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", fmt.Errorf("request error: %v", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-
-	res, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("Ollama not responding - check if 'ollama serve' is running: %v", err)
+		return "", err
 	}
 	defer res.Body.Close()
 
-	if res.StatusCode != 200 {
-		body, _ := ioutil.ReadAll(res.Body)
-		return "", fmt.Errorf("Ollama error %d: %s", res.StatusCode, string(body))
-	}
-
-	body, err := ioutil.ReadAll(res.Body)
+	body, err := io.ReadAll(res.Body)
 	if err != nil {
+		if ctx.Err() != nil {
+			return "", contextError(ctx)
+		}
 		return "", fmt.Errorf("response reading error: %v", err)
 	}
 
@@ -62,33 +56,92 @@ func AskQuestion(question string) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("JSON parse error: %v", err)
 	}
+	if response.Error != "" {
+		return "", fmt.Errorf("Ollama error: %s", response.Error)
+	}
 
 	return response.Response, nil
 }
 
-func AskWithContext(question, context string) (string, error) {
-	fullPrompt := fmt.Sprintf("Context: %s\n\nQuestion: %s", context, question)
-	return AskQuestion(fullPrompt)
-}
-
-func CheckOllamaStatus() error {
-	const OLLAMA_URL = "http://localhost:11434/api/tags"
-	
-	client := &http.Client{}
-	req, err := http.NewRequest("GET", OLLAMA_URL, nil)
-	if err != nil {
-		return fmt.Errorf("request error: %v", err)
+// GenerateStream sends the prompt with streaming enabled and calls
+// onChunk with every partial token as it arrives. The full response is
+// returned once Ollama reports done; when ctx is cancelled, the part
+// received so far is returned with the context's error.
+func (c *Client) GenerateStream(ctx context.Context, prompt string, onChunk func(string), overrides ...Options) (string, error) {
+	options, keepAlive := c.requestOptions(overrides)
+	requestBody := OllamaRequest{
+		Model:     c.Model,
+		Prompt:    prompt,
+		Stream:    true,
+		Options:   options,
+		KeepAlive: keepAlive,
 	}
 
-	res, err := client.Do(req)
+	res, err := c.postJSON(ctx, "/api/generate", requestBody)
 	if err != nil {
-		return fmt.Errorf("Ollama is not running. Start it with: ollama serve")
+		return "", err
 	}
 	defer res.Body.Close()
 
-	if res.StatusCode != 200 {
-		return fmt.Errorf("Ollama not responding correctly")
+	return readStream(ctx, res.Body, onChunk)
+}
+
+// readStream decodes the NDJSON body of a streaming generate call until a
+// chunk with done set to true is received.
+func readStream(ctx context.Context, body io.Reader, onChunk func(string)) (string, error) {
+	var full strings.Builder
+	decoder := json.NewDecoder(body)
+
+	for {
+		var chunk OllamaResponse
+		if err := decoder.Decode(&chunk); err != nil {
+			if ctx.Err() != nil {
+				return full.String(), contextError(ctx)
+			}
+			if err == io.EOF {
+				return full.String(), fmt.Errorf("stream ended before completion")
+			}
+			return full.String(), fmt.Errorf("JSON parse error: %v", err)
+		}
+
+		if chunk.Error != "" {
+			return full.String(), fmt.Errorf("Ollama error: %s", chunk.Error)
+		}
+
+		if chunk.Response != "" {
+			full.WriteString(chunk.Response)
+			if onChunk != nil {
+				onChunk(chunk.Response)
+			}
+		}
+
+		if chunk.Done {
+			return full.String(), nil
+		}
 	}
+}
+
+// AskQuestion and the other package-level functions use DefaultClient.
+func AskQuestion(question string, overrides ...Options) (string, error) {
+	return DefaultClient.Generate(context.Background(), question, overrides...)
+}
+
+func AskQuestionStream(question string, onChunk func(string), overrides ...Options) (string, error) {
+	return DefaultClient.GenerateStream(context.Background(), question, onChunk, overrides...)
+}
 
-	return nil
+func AskWithContext(question, context string, overrides ...Options) (string, error) {
+	return AskQuestion(buildContextPrompt(question, context), overrides...)
+}
+
+func AskWithContextStream(question, context string, onChunk func(string), overrides ...Options) (string, error) {
+	return AskQuestionStream(buildContextPrompt(question, context), onChunk, overrides...)
+}
+
+func buildContextPrompt(question, context string) string {
+	return fmt.Sprintf("Context: %s\n\nQuestion: %s", context, question)
+}
+
+func CheckOllamaStatus() error {
+	return DefaultClient.CheckStatus(context.Background())
 }
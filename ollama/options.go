@@ -0,0 +1,58 @@
+package ollama
+
+import (
+	"time"
+)
+
+// Options are the generation parameters sent with a request. Nil and zero
+// fields are left to the model's defaults, so Temperature and Seed are
+// pointers: 0 is a meaningful value for both.
+type Options struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	// NumCtx is the context window in tokens.
+	NumCtx int `json:"num_ctx,omitempty"`
+	// NumPredict caps the number of tokens generated for the reply.
+	NumPredict int      `json:"num_predict,omitempty"`
+	Stop       []string `json:"stop,omitempty"`
+	Seed       *int     `json:"seed,omitempty"`
+	// KeepAlive is how long the model stays loaded after the request. It
+	// is sent next to the options rather than inside them; a negative
+	// value keeps the model loaded indefinitely.
+	KeepAlive *time.Duration `json:"-"`
+}
+
+// Float and Int return pointers for the optional fields of Options.
+func Float(v float64) *float64 { return &v }
+func Int(v int) *int           { return &v }
+
+// Merge returns o with every field that is set in override replaced.
+func (o Options) Merge(override Options) Options {
+	if override.Temperature != nil {
+		o.Temperature = override.Temperature
+	}
+	if override.TopP != nil {
+		o.TopP = override.TopP
+	}
+	if override.NumCtx != 0 {
+		o.NumCtx = override.NumCtx
+	}
+	if override.NumPredict != 0 {
+		o.NumPredict = override.NumPredict
+	}
+	if override.Stop != nil {
+		o.Stop = override.Stop
+	}
+	if override.Seed != nil {
+		o.Seed = override.Seed
+	}
+	if override.KeepAlive != nil {
+		o.KeepAlive = override.KeepAlive
+	}
+	return o
+}
+
+func (o Options) isZero() bool {
+	return o.Temperature == nil && o.TopP == nil && o.NumCtx == 0 &&
+		o.NumPredict == 0 && o.Stop == nil && o.Seed == nil
+}
@@ -0,0 +1,124 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Tool describes a function the model may call, in the schema of the chat
+// endpoint's tools field.
+type Tool struct {
+	Type     string       `json:"type"`
+	Function ToolFunction `json:"function"`
+}
+
+type ToolFunction struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Parameters  ToolParameters `json:"parameters"`
+}
+
+// ToolParameters is the JSON schema of a tool's arguments, always an
+// object.
+type ToolParameters struct {
+	Type       string                  `json:"type"`
+	Properties map[string]ToolProperty `json:"properties"`
+	Required   []string                `json:"required,omitempty"`
+}
+
+type ToolProperty struct {
+	Type        string   `json:"type"`
+	Description string   `json:"description"`
+	Enum        []string `json:"enum,omitempty"`
+}
+
+// NewTool builds a function tool whose arguments are the given properties.
+func NewTool(name, description string, properties map[string]ToolProperty, required ...string) Tool {
+	if properties == nil {
+		properties = map[string]ToolProperty{}
+	}
+	return Tool{
+		Type: "function",
+		Function: ToolFunction{
+			Name:        name,
+			Description: description,
+			Parameters: ToolParameters{
+				Type:       "object",
+				Properties: properties,
+				Required:   required,
+			},
+		},
+	}
+}
+
+// ToolCall is a call the model asked for in an assistant message.
+type ToolCall struct {
+	Function ToolCallFunction `json:"function"`
+}
+
+type ToolCallFunction struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// DecodeArguments decodes the call's arguments into v. Ollama sends them
+// as an object, but some models put the object in a string.
+func (c ToolCall) DecodeArguments(v interface{}) error {
+	args := c.Function.Arguments
+	if len(args) == 0 || string(args) == "null" {
+		args = json.RawMessage("{}")
+	}
+
+	var encoded string
+	if err := json.Unmarshal(args, &encoded); err == nil {
+		args = json.RawMessage(encoded)
+	}
+
+	if err := json.Unmarshal(args, v); err != nil {
+		return fmt.Errorf("invalid arguments for %s: %v", c.Function.Name, err)
+	}
+	return nil
+}
+
+// ToolResult is the message that hands a tool's output back to the model.
+func ToolResult(name, content string) ChatMessage {
+	return ChatMessage{Role: "tool", Content: content, ToolName: name}
+}
+
+type showResponse struct {
+	Capabilities []string `json:"capabilities"`
+}
+
+// SupportsTools reports whether the model accepts tools, from the
+// capabilities /api/show lists. Ollama versions that do not list
+// capabilities are assumed to support them.
+func (c *Client) SupportsTools(ctx context.Context) (bool, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	res, err := c.postJSON(ctx, "/api/show", map[string]string{"model": c.Model})
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+
+	var show showResponse
+	if err := json.NewDecoder(res.Body).Decode(&show); err != nil {
+		return false, fmt.Errorf("JSON parse error: %v", err)
+	}
+
+	if len(show.Capabilities) == 0 {
+		return true, nil
+	}
+	for _, capability := range show.Capabilities {
+		if capability == "tools" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func SupportsTools() (bool, error) {
+	return DefaultClient.SupportsTools(context.Background())
+}
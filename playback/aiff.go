@@ -0,0 +1,109 @@
+package playback
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// decodeAIFF reads uncompressed AIFF and AIFF-C files, which is what the
+// macOS say command writes.
+func decodeAIFF(data []byte) (*PCM, error) {
+	compressed := string(data[8:12]) == "AIFC"
+
+	var channels, bitDepth int
+	var sampleRate float64
+	var sound []byte
+	littleEndian := false
+
+	for offset := 12; offset+8 <= len(data); {
+		id := string(data[offset : offset+4])
+		size := int(binary.BigEndian.Uint32(data[offset+4 : offset+8]))
+		body := offset + 8
+		if body+size > len(data) {
+			size = len(data) - body
+		}
+		chunk := data[body : body+size]
+
+		switch id {
+		case "COMM":
+			if len(chunk) < 18 {
+				return nil, fmt.Errorf("invalid AIFF COMM chunk")
+			}
+			channels = int(binary.BigEndian.Uint16(chunk[0:2]))
+			bitDepth = int(binary.BigEndian.Uint16(chunk[6:8]))
+			sampleRate = extendedToFloat(chunk[8:18])
+
+			if compressed && len(chunk) >= 22 {
+				switch string(chunk[18:22]) {
+				case "NONE":
+				case "sowt":
+					littleEndian = true
+				default:
+					return nil, fmt.Errorf("unsupported AIFF-C compression %q", chunk[18:22])
+				}
+			}
+		case "SSND":
+			if len(chunk) < 8 {
+				return nil, fmt.Errorf("invalid AIFF SSND chunk")
+			}
+			dataOffset := int(binary.BigEndian.Uint32(chunk[0:4]))
+			if 8+dataOffset <= len(chunk) {
+				sound = chunk[8+dataOffset:]
+			}
+		}
+
+		// Chunks are padded to an even length.
+		offset = body + size + size%2
+	}
+
+	if channels == 0 || sampleRate == 0 || sound == nil {
+		return nil, fmt.Errorf("incomplete AIFF file")
+	}
+
+	bytesPerSample := (bitDepth + 7) / 8
+	if bytesPerSample < 1 || bytesPerSample > 4 {
+		return nil, fmt.Errorf("unsupported AIFF bit depth %d", bitDepth)
+	}
+
+	count := len(sound) / bytesPerSample
+	out := make([]byte, count*2)
+	for i := 0; i < count; i++ {
+		sample := sound[i*bytesPerSample : (i+1)*bytesPerSample]
+
+		// Keep the two most significant bytes of each sample.
+		var hi, lo byte
+		if littleEndian {
+			hi = sample[bytesPerSample-1]
+			if bytesPerSample > 1 {
+				lo = sample[bytesPerSample-2]
+			}
+		} else {
+			hi = sample[0]
+			if bytesPerSample > 1 {
+				lo = sample[1]
+			}
+		}
+
+		out[i*2] = lo
+		out[i*2+1] = hi
+	}
+
+	return &PCM{Data: out, SampleRate: int(math.Round(sampleRate)), Channels: channels}, nil
+}
+
+// extendedToFloat converts the 80-bit IEEE 754 extended precision number
+// AIFF uses for the sample rate.
+func extendedToFloat(b []byte) float64 {
+	exponent := int(binary.BigEndian.Uint16(b[0:2]) & 0x7FFF)
+	mantissa := binary.BigEndian.Uint64(b[2:10])
+	if exponent == 0 && mantissa == 0 {
+		return 0
+	}
+
+	value := math.Ldexp(float64(mantissa), exponent-16383-63)
+	if b[0]&0x80 != 0 {
+		value = -value
+	}
+	return value
+}
@@ -0,0 +1,117 @@
+package playback
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/go-audio/wav"
+	"github.com/hajimehoshi/go-mp3"
+)
+
+// PCM is decoded audio in the layout the sound card expects: interleaved
+// signed 16-bit little-endian samples.
+type PCM struct {
+	Data       []byte
+	SampleRate int
+	Channels   int
+}
+
+func (p *PCM) Duration() time.Duration {
+	return p.durationOf(len(p.Data))
+}
+
+func (p *PCM) bytesPerSecond() int {
+	return p.SampleRate * p.Channels * 2
+}
+
+func (p *PCM) durationOf(n int) time.Duration {
+	if p.bytesPerSecond() == 0 {
+		return 0
+	}
+	return time.Duration(n) * time.Second / time.Duration(p.bytesPerSecond())
+}
+
+// DecodeFile decodes an MP3, WAV or AIFF file. The format is detected from
+// the file header, not the extension.
+func DecodeFile(path string) (*PCM, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(data)
+}
+
+func Decode(data []byte) (*PCM, error) {
+	if len(data) < 12 {
+		return nil, fmt.Errorf("audio data too short")
+	}
+
+	switch {
+	case string(data[:4]) == "RIFF" && string(data[8:12]) == "WAVE":
+		return decodeWAV(data)
+	case string(data[:4]) == "FORM" && (string(data[8:12]) == "AIFF" || string(data[8:12]) == "AIFC"):
+		return decodeAIFF(data)
+	case string(data[:3]) == "ID3" || (data[0] == 0xFF && data[1]&0xE0 == 0xE0):
+		return decodeMP3(data)
+	default:
+		return nil, fmt.Errorf("unsupported audio format")
+	}
+}
+
+func decodeMP3(data []byte) (*PCM, error) {
+	decoder, err := mp3.NewDecoder(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("MP3 decoding error: %w", err)
+	}
+
+	pcm, err := io.ReadAll(decoder)
+	if err != nil {
+		return nil, fmt.Errorf("MP3 decoding error: %w", err)
+	}
+
+	// go-mp3 always produces 16-bit stereo.
+	return &PCM{Data: pcm, SampleRate: decoder.SampleRate(), Channels: 2}, nil
+}
+
+func decodeWAV(data []byte) (*PCM, error) {
+	decoder := wav.NewDecoder(bytes.NewReader(data))
+	if !decoder.IsValidFile() {
+		return nil, fmt.Errorf("invalid WAV file")
+	}
+
+	buf, err := decoder.FullPCMBuffer()
+	if err != nil {
+		return nil, fmt.Errorf("WAV decoding error: %w", err)
+	}
+
+	return &PCM{
+		Data:       toInt16LE(buf.Data, int(decoder.BitDepth)),
+		SampleRate: int(decoder.SampleRate),
+		Channels:   int(decoder.NumChans),
+	}, nil
+}
+
+// toInt16LE scales samples of the given bit depth to 16 bits.
+func toInt16LE(samples []int, bitDepth int) []byte {
+	var out bytes.Buffer
+	w := bufio.NewWriter(&out)
+
+	for _, sample := range samples {
+		switch {
+		case bitDepth == 8:
+			// 8-bit WAV is unsigned.
+			sample = (sample - 128) << 8
+		case bitDepth > 16:
+			sample >>= bitDepth - 16
+		}
+		binary.Write(w, binary.LittleEndian, int16(sample))
+	}
+
+	w.Flush()
+	return out.Bytes()
+}
@@ -0,0 +1,120 @@
+package playback
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"KevinGo/audio"
+
+	"github.com/gordonklaus/portaudio"
+)
+
+// OutputDevice, when set, plays clips through this PortAudio device
+// instead of the system default that oto uses. PortAudio must stay
+// initialized while it is set.
+var OutputDevice *portaudio.DeviceInfo
+
+// player is where a Stream writes its samples.
+type player interface {
+	Write(data []byte) (int, error)
+	Close() error
+}
+
+func newPlayer(pcm *PCM) (player, error) {
+	if OutputDevice != nil {
+		return openDevice(OutputDevice, pcm)
+	}
+
+	c, err := contextFor(pcm)
+	if err != nil {
+		return nil, err
+	}
+	return c.NewPlayer(), nil
+}
+
+// devicePlayer writes to a blocking PortAudio stream. Clips in a format
+// the device does not take are played in mono at its default rate.
+type devicePlayer struct {
+	stream *portaudio.Stream
+	buf    []int16
+	filled int
+
+	// channels is the clip's; rest holds the start of a frame that a
+	// Write ended in the middle of.
+	channels int
+	rest     []byte
+	// resampler is set when the clip is converted; it carries its place
+	// from one Write to the next.
+	resampler *audio.Resampler
+}
+
+func openDevice(dev *portaudio.DeviceInfo, pcm *PCM) (*devicePlayer, error) {
+	p := portaudio.LowLatencyParameters(nil, dev)
+	p.Output.Channels = pcm.Channels
+	p.Output.Latency = latency
+	p.SampleRate = float64(pcm.SampleRate)
+
+	d := &devicePlayer{channels: pcm.Channels}
+	if portaudio.IsFormatSupported(p, make([]int16, 1)) != nil {
+		p.Output.Channels = 1
+		p.SampleRate = dev.DefaultSampleRate
+		d.resampler = &audio.Resampler{From: pcm.SampleRate, To: int(dev.DefaultSampleRate)}
+	}
+
+	frames := int(p.SampleRate * (latency / 4).Seconds())
+	p.FramesPerBuffer = frames
+	d.buf = make([]int16, frames*p.Output.Channels)
+
+	stream, err := portaudio.OpenStream(p, d.buf)
+	if err != nil {
+		return nil, fmt.Errorf("audio device error: %s: %w", dev.Name, err)
+	}
+	if err := stream.Start(); err != nil {
+		stream.Close()
+		return nil, fmt.Errorf("audio device error: %s: %w", dev.Name, err)
+	}
+
+	d.stream = stream
+	return d, nil
+}
+
+// Write queues whole buffers on the device; what does not fill one waits
+// for the next call.
+func (d *devicePlayer) Write(data []byte) (int, error) {
+	n := len(data)
+	if len(d.rest) > 0 {
+		data = append(d.rest, data...)
+	}
+	whole := len(data) - len(data)%(2*d.channels)
+
+	samples := make([]int16, whole/2)
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(data[i*2:]))
+	}
+	d.rest = append(d.rest[:0], data[whole:]...)
+
+	if d.resampler != nil {
+		samples = d.resampler.Resample(audio.Downmix(samples, d.channels))
+	}
+
+	for len(samples) > 0 {
+		n := copy(d.buf[d.filled:], samples)
+		d.filled += n
+		samples = samples[n:]
+
+		if d.filled == len(d.buf) {
+			if err := d.stream.Write(); err != nil && err != portaudio.OutputUnderflowed {
+				return 0, err
+			}
+			d.filled = 0
+		}
+	}
+
+	return n, nil
+}
+
+// Close stops right away, like closing an oto player.
+func (d *devicePlayer) Close() error {
+	d.stream.Abort()
+	return d.stream.Close()
+}
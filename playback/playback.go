@@ -0,0 +1,230 @@
+package playback
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/hajimehoshi/oto"
+)
+
+// Progress is reported while a clip is playing.
+type Progress struct {
+	Played time.Duration
+	Total  time.Duration
+}
+
+// latency is the size of the output buffer. It bounds how long playback
+// continues after a cancellation.
+const latency = 100 * time.Millisecond
+
+// oto allows a single context per process, so it is shared and only
+// recreated when a clip needs a different format.
+var (
+	mu         sync.Mutex
+	otoContext *oto.Context
+	sampleRate int
+	channels   int
+)
+
+// output tracks the level of the last two chunks handed to the device,
+// which is roughly what is coming out of the speakers right now.
+var output struct {
+	sync.Mutex
+	current, previous float64
+}
+
+// OutputLevel returns the RMS level, on the int16 scale, of the audio that
+// is being played, or 0 when nothing is playing. Barge-in uses it to tell
+// the user's voice from Kira's own echo.
+func OutputLevel() float64 {
+	output.Lock()
+	defer output.Unlock()
+	return max(output.current, output.previous)
+}
+
+func setOutputLevel(level float64) {
+	output.Lock()
+	output.previous, output.current = output.current, level
+	output.Unlock()
+}
+
+func resetOutputLevel() {
+	output.Lock()
+	output.previous, output.current = 0, 0
+	output.Unlock()
+}
+
+func levelOf(data []byte) float64 {
+	if len(data) < 2 {
+		return 0
+	}
+
+	var sum float64
+	for i := 0; i+1 < len(data); i += 2 {
+		v := float64(int16(binary.LittleEndian.Uint16(data[i:])))
+		sum += v * v
+	}
+
+	return math.Sqrt(sum / float64(len(data)/2))
+}
+
+func contextFor(pcm *PCM) (*oto.Context, error) {
+	if otoContext != nil && sampleRate == pcm.SampleRate && channels == pcm.Channels {
+		return otoContext, nil
+	}
+
+	if otoContext != nil {
+		otoContext.Close()
+		otoContext = nil
+	}
+
+	bufferSize := int(float64(pcm.bytesPerSecond())*latency.Seconds()) &^ 3
+	c, err := oto.NewContext(pcm.SampleRate, pcm.Channels, 2, bufferSize)
+	if err != nil {
+		return nil, fmt.Errorf("audio device error: %w", err)
+	}
+
+	otoContext, sampleRate, channels = c, pcm.SampleRate, pcm.Channels
+	return c, nil
+}
+
+// PlayFile decodes and plays an MP3, WAV or AIFF file through the output
+// device.
+func PlayFile(ctx context.Context, path string, onProgress func(Progress)) error {
+	pcm, err := DecodeFile(path)
+	if err != nil {
+		return err
+	}
+	return Play(ctx, pcm, onProgress)
+}
+
+// Play blocks until the clip has been played or ctx is done, in which case
+// playback stops within the output latency and ctx.Err() is returned.
+// onProgress, when set, is called as the clip plays.
+func Play(ctx context.Context, pcm *PCM, onProgress func(Progress)) error {
+	stream := OpenStream()
+	defer stream.Abort()
+
+	if err := stream.Write(ctx, pcm, onProgress); err != nil {
+		return err
+	}
+
+	if err := stream.Close(ctx); err != nil {
+		return err
+	}
+
+	if onProgress != nil {
+		total := pcm.Duration()
+		onProgress(Progress{Played: total, Total: total})
+	}
+
+	return nil
+}
+
+// Stream plays consecutive clips through one player, so sentences that
+// are synthesized separately are heard without gaps between them.
+type Stream struct {
+	player player
+	pcm    *PCM
+	locked bool
+}
+
+// OpenStream reserves the output device until Close or Abort is called.
+func OpenStream() *Stream {
+	mu.Lock()
+	return &Stream{locked: true}
+}
+
+// Write blocks until the clip has been handed to the device or ctx is done.
+// The last part of the clip is still playing when Write returns; Close
+// waits for it.
+func (s *Stream) Write(ctx context.Context, pcm *PCM, onProgress func(Progress)) error {
+	if s.pcm != nil && (s.pcm.SampleRate != pcm.SampleRate || s.pcm.Channels != pcm.Channels) {
+		if err := s.drain(ctx); err != nil {
+			return err
+		}
+	}
+
+	if s.player == nil {
+		p, err := newPlayer(pcm)
+		if err != nil {
+			return err
+		}
+		s.player = p
+	}
+	s.pcm = pcm
+
+	total := pcm.Duration()
+	chunk := int(float64(pcm.bytesPerSecond())*(latency/2).Seconds()) &^ 3
+
+	for written := 0; written < len(pcm.Data); {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		end := min(written+chunk, len(pcm.Data))
+		setOutputLevel(levelOf(pcm.Data[written:end]))
+		n, err := s.player.Write(pcm.Data[written:end])
+		written += n
+		if err != nil {
+			return fmt.Errorf("audio output error: %w", err)
+		}
+
+		if onProgress != nil {
+			played := pcm.durationOf(written) - latency
+			onProgress(Progress{Played: max(played, 0), Total: total})
+		}
+	}
+
+	return nil
+}
+
+// Close waits until everything written has been heard and releases the
+// device.
+func (s *Stream) Close(ctx context.Context) error {
+	defer s.Abort()
+	return s.drain(ctx)
+}
+
+// Abort stops playback right away and releases the device. It is safe to
+// call after Close.
+func (s *Stream) Abort() {
+	resetOutputLevel()
+	if s.player != nil {
+		s.player.Close()
+		s.player = nil
+	}
+	if s.locked {
+		s.locked = false
+		mu.Unlock()
+	}
+}
+
+func (s *Stream) drain(ctx context.Context) error {
+	if s.player == nil {
+		return nil
+	}
+
+	// The player only starts once its buffer is full, so push silence to
+	// flush the tail of the last clip, then wait for it to be heard.
+	silence := make([]byte, int(float64(s.pcm.bytesPerSecond())*latency.Seconds())&^3)
+	_, err := s.player.Write(silence)
+	if err == nil {
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+		case <-time.After(latency):
+		}
+	} else {
+		err = fmt.Errorf("audio output error: %w", err)
+	}
+
+	resetOutputLevel()
+	s.player.Close()
+	s.player = nil
+	return err
+}
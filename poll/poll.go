@@ -1,47 +1,185 @@
 package poll
 
 import (
+	"KevinGo/apierror"
 	"KevinGo/transcribe"
+	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 )
 
-func StartPolling() string {
-	const API_KEY = "Your Key"
-	const TRANSCRIBE_URL = "https://api.assemblyai.com/v2/transcript"
-	transcriptID := transcribe.Transcribe()
+var (
+	ErrMissingTranscriptID = errors.New("missing transcript ID")
+	// ErrTimeout is apierror.ErrTimeout, so either can be matched.
+	ErrTimeout          = apierror.ErrTimeout
+	ErrUnexpectedStatus = errors.New("unexpected transcript status")
+	// ErrNoSpeech is returned for a completed transcript without text.
+	ErrNoSpeech = errors.New("no speech detected")
+)
+
+// TranscriptError is returned when AssemblyAI reports that the transcription
+// itself failed.
+type TranscriptError struct {
+	ID      string
+	Message string
+}
+
+func (e *TranscriptError) Error() string {
+	return fmt.Sprintf("transcript %s failed: %s", e.ID, e.Message)
+}
+
+// Options tune the polling. Fields left at zero take their value from
+// DefaultOptions.
+type Options struct {
+	// APIKey is the AssemblyAI key.
+	APIKey string
+	// Interval is the wait before the second poll.
+	Interval time.Duration
+	// MaxInterval caps the wait after repeated backoff.
+	MaxInterval time.Duration
+	// Multiplier grows the wait after every queued or processing answer.
+	Multiplier float64
+	// Timeout bounds the whole polling. A negative value waits for as
+	// long as ctx allows.
+	Timeout time.Duration
+}
+
+// withDefaults fills the unset fields from DefaultOptions.
+func (o Options) withDefaults() Options {
+	defaults := DefaultOptions()
+	if o.Interval <= 0 {
+		o.Interval = defaults.Interval
+	}
+	if o.MaxInterval <= 0 {
+		o.MaxInterval = defaults.MaxInterval
+	}
+	if o.Multiplier == 0 {
+		o.Multiplier = defaults.Multiplier
+	}
+	if o.Timeout == 0 {
+		o.Timeout = defaults.Timeout
+	}
+	return o
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Interval:    500 * time.Millisecond,
+		MaxInterval: 5 * time.Second,
+		Multiplier:  1.5,
+		Timeout:     2 * time.Minute,
+	}
+}
+
+type transcriptResponse struct {
+	Status string `json:"status"`
+	Text   string `json:"text"`
+	Error  string `json:"error"`
+}
+
+// StartPolling waits for the transcript to complete and returns its text.
+// It stops when the transcript fails, the timeout elapses or ctx is done.
+// HTTP failures are *apierror.StatusError values.
+func StartPolling(ctx context.Context, transcriptID string, opts Options) (string, error) {
+	if transcriptID == "" {
+		return "", ErrMissingTranscriptID
+	}
 
-	pollingURL := TRANSCRIBE_URL + "/" + transcriptID
+	opts = opts.withDefaults()
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	pollingURL := transcribe.TranscriptURL + "/" + transcriptID
 	client := &http.Client{}
+	wait := opts.Interval
 
 	for {
-		req, err := http.NewRequest("GET", pollingURL, nil)
+		req, err := http.NewRequestWithContext(ctx, "GET", pollingURL, nil)
 		if err != nil {
-			log.Println(err)
-			return ""
+			return "", fmt.Errorf("error creating request: %w", err)
 		}
 
 		req.Header.Set("content-type", "application/json")
-		req.Header.Set("authorization", API_KEY)
+		req.Header.Set("authorization", opts.APIKey)
 
-		res, err := client.Do(req)
+		result, err := fetch(client, req)
 		if err != nil {
-			log.Println(err)
-			return ""
+			if ctxErr := contextError(ctx); ctxErr != nil {
+				return "", ctxErr
+			}
+			return "", err
 		}
 
-		var result map[string]interface{}
-		json.NewDecoder(res.Body).Decode(&result)
-		res.Body.Close()
-
-		status := result["status"].(string)
+		switch result.Status {
+		case "completed":
+			if strings.TrimSpace(result.Text) == "" {
+				return "", ErrNoSpeech
+			}
+			return result.Text, nil
+		case "error":
+			return "", &TranscriptError{ID: transcriptID, Message: result.Error}
+		case "queued", "processing":
+		default:
+			return "", fmt.Errorf("%w: %q", ErrUnexpectedStatus, result.Status)
+		}
 
-		if status == "completed" {
-			return result["text"].(string)
-			break
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return "", contextError(ctx)
+		case <-timer.C:
 		}
+
+		wait = nextInterval(wait, opts)
+	}
+}
+
+func fetch(client *http.Client, req *http.Request) (*transcriptResponse, error) {
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, apierror.Transport("AssemblyAI", err)
+	}
+	defer res.Body.Close()
+
+	if err := apierror.Check("AssemblyAI", res); err != nil {
+		return nil, err
 	}
 
-	return ""
+	var result transcriptResponse
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("error parsing JSON: %w", err)
+	}
+
+	return &result, nil
+}
+
+func nextInterval(wait time.Duration, opts Options) time.Duration {
+	if opts.Multiplier > 1 {
+		wait = time.Duration(float64(wait) * opts.Multiplier)
+	}
+	if opts.MaxInterval > 0 && wait > opts.MaxInterval {
+		wait = opts.MaxInterval
+	}
+	return wait
+}
+
+// contextError reports why ctx ended, turning an expired deadline into
+// ErrTimeout so callers can tell it apart from a cancellation.
+func contextError(ctx context.Context) error {
+	switch ctx.Err() {
+	case nil:
+		return nil
+	case context.DeadlineExceeded:
+		return fmt.Errorf("%w: %w", ErrTimeout, ctx.Err())
+	default:
+		return ctx.Err()
+	}
 }
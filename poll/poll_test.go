@@ -0,0 +1,179 @@
+package poll_test
+
+import (
+	"KevinGo/apierror"
+	"KevinGo/poll"
+	"KevinGo/poll/polltest"
+	"KevinGo/transcribe"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func fastOptions() poll.Options {
+	return poll.Options{
+		APIKey:      "test",
+		Interval:    time.Millisecond,
+		MaxInterval: 5 * time.Millisecond,
+		Multiplier:  2,
+		Timeout:     5 * time.Second,
+	}
+}
+
+func TestStartPolling(t *testing.T) {
+	tests := []struct {
+		name     string
+		steps    []polltest.Step
+		opts     func(*poll.Options)
+		want     string
+		requests int
+		check    func(t *testing.T, err error)
+	}{
+		{
+			name: "queued, processing, completed",
+			steps: []polltest.Step{
+				{Status: "queued"},
+				{Status: "processing"},
+				{Status: "completed", Text: "What's the weather in Cluj?"},
+			},
+			want:     "What's the weather in Cluj?",
+			requests: 3,
+		},
+		{
+			name:  "transcript error",
+			steps: []polltest.Step{{Status: "processing"}, {Status: "error", Error: "audio too short"}},
+			check: func(t *testing.T, err error) {
+				var transcriptErr *poll.TranscriptError
+				if !errors.As(err, &transcriptErr) || transcriptErr.Message != "audio too short" {
+					t.Errorf("err = %v, want a TranscriptError with the message", err)
+				}
+			},
+		},
+		{
+			name:  "completed without text",
+			steps: []polltest.Step{{Status: "completed", Text: "  "}},
+			check: wantIs(poll.ErrNoSpeech),
+		},
+		{
+			name:  "unauthorized",
+			steps: []polltest.Step{{StatusCode: http.StatusUnauthorized, Body: `{"error": "Invalid API key"}`}},
+			check: func(t *testing.T, err error) {
+				var statusErr *apierror.StatusError
+				if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusUnauthorized || statusErr.Message != "Invalid API key" {
+					t.Errorf("err = %v, want a 401 StatusError with the API message", err)
+				}
+				if !errors.Is(err, apierror.ErrAuth) {
+					t.Errorf("err = %v, want it to match ErrAuth", err)
+				}
+			},
+		},
+		{
+			name:  "server error",
+			steps: []polltest.Step{{Status: "queued"}, {StatusCode: http.StatusBadGateway}},
+			check: wantIs(apierror.ErrUnavailable),
+		},
+		{
+			name:  "unknown status",
+			steps: []polltest.Step{{Status: "paused"}},
+			check: wantIs(poll.ErrUnexpectedStatus),
+		},
+		{
+			name:  "deadline",
+			steps: []polltest.Step{{Status: "queued"}},
+			opts:  func(o *poll.Options) { o.Timeout = 50 * time.Millisecond },
+			check: func(t *testing.T, err error) {
+				if !errors.Is(err, poll.ErrTimeout) || !errors.Is(err, apierror.ErrTimeout) {
+					t.Errorf("err = %v, want ErrTimeout", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := polltest.NewServer(tt.steps...)
+			defer srv.Close()
+			transcribe.TranscriptURL = srv.TranscriptURL()
+
+			opts := fastOptions()
+			if tt.opts != nil {
+				tt.opts(&opts)
+			}
+
+			text, err := poll.StartPolling(context.Background(), "abc", opts)
+			if tt.check != nil {
+				if text != "" {
+					t.Errorf("text = %q, want none", text)
+				}
+				tt.check(t, err)
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("StartPolling: %v", err)
+			}
+			if text != tt.want {
+				t.Errorf("text = %q, want %q", text, tt.want)
+			}
+			if srv.Requests() != tt.requests {
+				t.Errorf("requests = %d, want %d", srv.Requests(), tt.requests)
+			}
+		})
+	}
+}
+
+func wantIs(target error) func(*testing.T, error) {
+	return func(t *testing.T, err error) {
+		t.Helper()
+		if !errors.Is(err, target) {
+			t.Errorf("err = %v, want %v", err, target)
+		}
+	}
+}
+
+func TestStartPollingCanceled(t *testing.T) {
+	srv := polltest.NewServer(polltest.Step{Status: "processing"})
+	defer srv.Close()
+	transcribe.TranscriptURL = srv.TranscriptURL()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(30*time.Millisecond, cancel)
+
+	_, err := poll.StartPolling(ctx, "abc", fastOptions())
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if errors.Is(err, poll.ErrTimeout) {
+		t.Errorf("err = %v, a cancellation must not look like a timeout", err)
+	}
+}
+
+func TestStartPollingEmptyID(t *testing.T) {
+	srv := polltest.NewServer()
+	defer srv.Close()
+	transcribe.TranscriptURL = srv.TranscriptURL()
+
+	if _, err := poll.StartPolling(context.Background(), "", fastOptions()); !errors.Is(err, poll.ErrMissingTranscriptID) {
+		t.Errorf("err = %v, want ErrMissingTranscriptID", err)
+	}
+	if srv.Requests() != 0 {
+		t.Errorf("requests = %d, want none for an empty ID", srv.Requests())
+	}
+}
+
+// A zero Options must still wait between polls and give up eventually.
+func TestStartPollingZeroOptions(t *testing.T) {
+	srv := polltest.NewServer(polltest.Step{Status: "queued"})
+	defer srv.Close()
+	transcribe.TranscriptURL = srv.TranscriptURL()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	poll.StartPolling(ctx, "abc", poll.Options{APIKey: "test"})
+	if n := srv.Requests(); n > 2 {
+		t.Errorf("requests = %d in 300ms, want the default interval between polls", n)
+	}
+}
@@ -0,0 +1,97 @@
+// Package polltest provides a local stand-in for AssemblyAI's transcript
+// endpoint, so the state transitions handled by poll.StartPolling can be
+// exercised without network access or an API key.
+//
+//	srv := polltest.NewServer(
+//		polltest.Step{Status: "queued"},
+//		polltest.Step{Status: "processing"},
+//		polltest.Step{Status: "completed", Text: "hello"},
+//	)
+//	defer srv.Close()
+//	transcribe.TranscriptURL = srv.TranscriptURL()
+package polltest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// Step is one answer of the stand-in. When StatusCode is set, the server
+// answers with that HTTP status and Body instead of a transcript.
+type Step struct {
+	Status     string
+	Text       string
+	Error      string
+	StatusCode int
+	Body       string
+}
+
+// Server answers the n-th poll with the n-th step and repeats the last step
+// once the script is exhausted.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	steps    []Step
+	requests int
+}
+
+func NewServer(steps ...Step) *Server {
+	s := &Server{steps: steps}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// TranscriptURL is the value to assign to transcribe.TranscriptURL.
+func (s *Server) TranscriptURL() string {
+	return s.URL + "/v2/transcript"
+}
+
+// Requests returns how many polls the server has answered.
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/v2/transcript/")
+	if r.Method != http.MethodGet || id == "" || id == r.URL.Path {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Header.Get("authorization") == "" {
+		http.Error(w, `{"error": "Authentication error, API token missing/invalid"}`, http.StatusUnauthorized)
+		return
+	}
+
+	s.mu.Lock()
+	step := Step{Status: "queued"}
+	if len(s.steps) > 0 {
+		index := s.requests
+		if index >= len(s.steps) {
+			index = len(s.steps) - 1
+		}
+		step = s.steps[index]
+	}
+	s.requests++
+	s.mu.Unlock()
+
+	if step.StatusCode != 0 {
+		w.WriteHeader(step.StatusCode)
+		w.Write([]byte(step.Body))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"id":     id,
+		"status": step.Status,
+		"text":   step.Text,
+		"error":  step.Error,
+	})
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"KevinGo/audio"
+	"KevinGo/stt"
+	"KevinGo/vad"
+	"KevinGo/wakeword"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// hear records the next request and returns its transcript. When there
+// is a streamer, requests heard with voice activity detection are
+// transcribed while the user talks; the others are recorded first and
+// then handed to the transcriber.
+func hear(transcriber stt.Transcriber, streamer stt.Streamer, wake *wakeword.Detector, interrupted *interruption) (string, error) {
+	if streamer != nil && wake == nil && cfg.Audio.ListenMode != "ptt" {
+		return streamRequest(streamer, interrupted)
+	}
+
+	var rec audio.Recording
+	var err error
+	if interrupted != nil {
+		rec, err = recordInterruption(interrupted)
+	} else {
+		rec, err = recordUtterance(wake)
+	}
+	if errors.Is(err, errNothingHeard) {
+		return "", err
+	} else if err != nil {
+		return "", fmt.Errorf("Recording error: %w", err)
+	}
+
+	archiveRecording(rec)
+
+	fmt.Printf("🔄 Transcribing audio with %s...\n", transcriber.Name())
+	text, err := transcriber.Transcribe(context.Background(), rec)
+	if err != nil {
+		return "", fmt.Errorf("Could not transcribe audio: %w", err)
+	}
+	return text, nil
+}
+
+// recordUtterance captures one request from the microphone: after the
+// wake word when wake is set, otherwise hands-free with voice activity
+// detection or push-to-talk.
+func recordUtterance(wake *wakeword.Detector) (audio.Recording, error) {
+	var samples []int16
+	var err error
+
+	switch {
+	case wake != nil:
+		samples, err = recordAfterWakeWord(wake)
+	case cfg.Audio.ListenMode == "ptt":
+		return recordPushToTalk(false)
+	default:
+		samples, err = recordUntilSilence()
+	}
+	if err != nil {
+		return audio.Recording{}, err
+	}
+
+	return recording(samples), nil
+}
+
+// recordPushToTalk records between two presses of Enter. When started is
+// set, the first press already happened, for example to interrupt an answer.
+func recordPushToTalk(started bool) (audio.Recording, error) {
+	if !started {
+		fmt.Println("🎤 Press Enter to start recording...")
+		waitForEnter()
+	}
+
+	in := make([]int16, 64)
+	stream, err := openInput(in)
+	if err != nil {
+		return audio.Recording{}, err
+	}
+
+	var samples []int16
+
+	fmt.Println("🎙 Recording... Press Enter to stop.")
+	stream.Start()
+	stopChan := make(chan bool)
+
+	go func() {
+		for {
+			select {
+			case <-stopChan:
+				return
+			default:
+				stream.Read()
+				samples = append(samples, in...)
+			}
+		}
+	}()
+
+	waitForEnter()
+	stopChan <- true
+
+	stream.Stop()
+	stream.Close()
+
+	return recording(samples), nil
+}
+
+// errNothingHeard is returned when nobody spoke before the capture gave up.
+var errNothingHeard = errors.New("no request heard")
+
+// recordUntilSilence listens until the user speaks and returns the
+// utterance once they have been quiet for the configured silence.
+func recordUntilSilence() ([]int16, error) {
+	mic, err := openMicrophone(512)
+	if err != nil {
+		return nil, err
+	}
+	defer mic.Close()
+
+	return captureUtterance(mic.frames, nil, 0)
+}
+
+// captureUtterance reads frames until a complete utterance was heard. seed
+// is audio that was already captured and belongs to the request. A short
+// pre-roll is kept so the first syllable is not clipped. When patience is
+// positive, the capture gives up if no speech starts within it.
+func captureUtterance(frames <-chan []int16, seed []int16, patience time.Duration) ([]int16, error) {
+	detector := vad.New(vadConfig())
+	preRoll := cfg.Audio.SampleRate * 3 / 10
+	patienceSamples := int(patience.Seconds() * float64(cfg.Audio.SampleRate))
+
+	var pending, samples []int16
+	recording := false
+	waited := 0
+
+	if seed == nil {
+		fmt.Println("🎤 Listening... start speaking.")
+	}
+
+	process := func(frame []int16) ([]int16, bool) {
+		event := detector.Process(frame)
+
+		if recording {
+			samples = append(samples, frame...)
+		} else {
+			pending = append(pending, frame...)
+			if len(pending) > preRoll {
+				pending = pending[len(pending)-preRoll:]
+			}
+		}
+
+		switch event {
+		case vad.SpeechStarted:
+			if !recording {
+				fmt.Println("🎙 Recording... stop talking to finish.")
+				recording = true
+				samples = append(samples[:0], pending...)
+				pending = pending[:0]
+			}
+		case vad.Discarded:
+			fmt.Println("🤫 Ignored a short noise, still listening...")
+			recording = false
+			samples = samples[:0]
+		case vad.MaxLengthReached:
+			fmt.Println("⏱️ Maximum utterance length reached.")
+			return samples, true
+		case vad.SpeechEnded:
+			return samples, true
+		}
+
+		return nil, false
+	}
+
+	for start := 0; start < len(seed); start += 512 {
+		if utterance, done := process(seed[start:min(start+512, len(seed))]); done {
+			return utterance, nil
+		}
+	}
+
+	for frame := range frames {
+		if utterance, done := process(frame); done {
+			return utterance, nil
+		}
+
+		if !recording {
+			waited += len(frame)
+			if patienceSamples > 0 && waited >= patienceSamples {
+				return nil, errNothingHeard
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("microphone stopped before the utterance ended")
+}
+
+func vadConfig() vad.Config {
+	vadCfg := vad.DefaultConfig(cfg.Audio.SampleRate)
+	vadCfg.Threshold = cfg.VAD.Threshold
+	vadCfg.Silence = cfg.VAD.Silence
+	vadCfg.MaxUtterance = cfg.VAD.MaxUtterance
+	vadCfg.MinSpeech = cfg.VAD.MinSpeech
+	return vadCfg
+}
+
+func recording(samples []int16) audio.Recording {
+	return audio.Recording{Samples: samples, SampleRate: cfg.Audio.SampleRate, Channels: 1}
+}
+
+// archiveRecording saves a WAV copy of the request when
+// audio.archive_dir is set. Every turn gets its own file.
+func archiveRecording(rec audio.Recording) {
+	if cfg.Audio.ArchiveDir == "" {
+		return
+	}
+
+	path, err := writeArchive(rec)
+	if err != nil {
+		log.Printf("⚠️ Could not archive the recording: %v", err)
+		return
+	}
+	fmt.Printf("🗄️ Recording saved to %s\n", path)
+}
+
+func writeArchive(rec audio.Recording) (string, error) {
+	if err := os.MkdirAll(cfg.Audio.ArchiveDir, 0755); err != nil {
+		return "", err
+	}
+
+	f, err := os.CreateTemp(cfg.Audio.ArchiveDir, time.Now().Format("request-20060102-150405-*.wav"))
+	if err != nil {
+		return "", err
+	}
+
+	err = rec.Write(f, audio.WAV)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+
+	return f.Name(), nil
+}
@@ -0,0 +1,73 @@
+package skill
+
+import (
+	"context"
+	"strings"
+)
+
+// Classification is a model's reading of a question: what the user wants
+// and the details they gave, such as "city" and "date".
+type Classification struct {
+	Intent     string            `json:"intent"`
+	Entities   map[string]string `json:"entities"`
+	Confidence float64           `json:"confidence"`
+}
+
+// Entity returns the named detail, or "" when the question did not give
+// it.
+func (c Classification) Entity(name string) string {
+	return strings.TrimSpace(c.Entities[name])
+}
+
+// IntentInfo describes a skill to a classifier.
+type IntentInfo struct {
+	Name        string
+	Description string
+}
+
+// Classifier reads a question once before the skills match it, for
+// example by asking the LLM.
+type Classifier interface {
+	Classify(ctx context.Context, query string, intents []IntentInfo) (Classification, error)
+}
+
+// Describer is implemented by skills that explain to a classifier which
+// questions they handle. Skills without a description are matched by
+// their other matchers only.
+type Describer interface {
+	Description() string
+}
+
+// General is the intent for questions no skill handles.
+const General = "general"
+
+type classificationKey struct{}
+
+// WithClassification returns a copy of ctx that carries c to the skills.
+func WithClassification(ctx context.Context, c Classification) context.Context {
+	return context.WithValue(ctx, classificationKey{}, c)
+}
+
+// ClassificationFrom returns the classification of the question being
+// handled, if the classifier was confident about it.
+func ClassificationFrom(ctx context.Context) (Classification, bool) {
+	c, ok := ctx.Value(classificationKey{}).(Classification)
+	return c, ok
+}
+
+// Intent scores 1 when the classifier read the question as the named
+// intent, and 0 when it read it as another one. Without a confident
+// classification it uses fallback, so keywords still work when the
+// classifier is off, slow or unsure.
+func Intent(name string, fallback Matcher) Matcher {
+	return MatcherFunc(func(ctx context.Context, query string) float64 {
+		c, ok := ClassificationFrom(ctx)
+		if !ok {
+			return fallback.Score(ctx, query)
+		}
+		if c.Intent == name {
+			return 1
+		}
+		return 0
+	})
+}
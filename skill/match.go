@@ -0,0 +1,80 @@
+package skill
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Matcher scores how well a question fits, from 0 to 1.
+type Matcher interface {
+	Score(ctx context.Context, query string) float64
+}
+
+// MatcherFunc adapts a function to a Matcher.
+type MatcherFunc func(ctx context.Context, query string) float64
+
+func (f MatcherFunc) Score(ctx context.Context, query string) float64 {
+	return f(ctx, query)
+}
+
+// Keywords adds weight for every listed word that appears in the question
+// as a whole word, up to 1. Hyphenated words count as one, so "hot" does
+// not match "hot-headed" and "sun" does not match "sunday".
+func Keywords(weight float64, words ...string) Matcher {
+	set := make(map[string]bool, len(words))
+	for _, word := range words {
+		set[strings.ToLower(word)] = true
+	}
+
+	return MatcherFunc(func(_ context.Context, query string) float64 {
+		score := 0.0
+		seen := map[string]bool{}
+		for _, word := range Words(query) {
+			if set[word] && !seen[word] {
+				seen[word] = true
+				score += weight
+			}
+		}
+		return min(score, 1)
+	})
+}
+
+// Pattern scores the questions matching a regular expression.
+func Pattern(score float64, expr string) Matcher {
+	re := regexp.MustCompile(expr)
+
+	return MatcherFunc(func(_ context.Context, query string) float64 {
+		if re.MatchString(query) {
+			return score
+		}
+		return 0
+	})
+}
+
+// Any returns the best score of its matchers.
+func Any(matchers ...Matcher) Matcher {
+	return MatcherFunc(func(ctx context.Context, query string) float64 {
+		best := 0.0
+		for _, m := range matchers {
+			best = max(best, m.Score(ctx, query))
+		}
+		return best
+	})
+}
+
+// Words splits a question into lowercase words, keeping apostrophes and
+// hyphens inside words.
+func Words(query string) []string {
+	var words []string
+	for _, field := range strings.Fields(strings.ToLower(query)) {
+		word := strings.TrimFunc(field, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if word != "" {
+			words = append(words, word)
+		}
+	}
+	return words
+}
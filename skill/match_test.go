@@ -0,0 +1,84 @@
+package skill_test
+
+import (
+	"KevinGo/skill"
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestKeywords(t *testing.T) {
+	strong := skill.Keywords(1, "weather", "hot", "sun")
+	weak := skill.Keywords(0.3, "cold", "outside", "jacket", "coat")
+
+	tests := []struct {
+		matcher skill.Matcher
+		query   string
+		want    float64
+	}{
+		{strong, "What's the weather like?", 1},
+		{strong, "Is it hot?", 1},
+		{strong, "What are your Sunday plans?", 0},
+		{strong, "My brother is so hot-headed", 0},
+		{strong, "Sunshine and sunsets", 0},
+		{weak, "I have a cold", 0.3},
+		{weak, "Is it cold outside?", 0.6},
+		{weak, "cold, cold, cold", 0.3},
+		{weak, "Cold outside: jacket or coat?", 1},
+	}
+
+	for _, tt := range tests {
+		if got := tt.matcher.Score(context.Background(), tt.query); !near(got, tt.want) {
+			t.Errorf("Score(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestWords(t *testing.T) {
+	got := skill.Words(`What's the weather in Cluj-Napoca, "today"?`)
+	want := []string{"what's", "the", "weather", "in", "cluj-napoca", "today"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Words = %q, want %q", got, want)
+	}
+}
+
+func TestAnyTakesTheBestScore(t *testing.T) {
+	m := skill.Any(
+		skill.Keywords(0.3, "cold"),
+		skill.Pattern(0.9, `(?i)\bhow\s+cold\s+is\s+it\b`),
+	)
+
+	if got := m.Score(context.Background(), "How cold is it?"); got != 0.9 {
+		t.Errorf("Score = %v, want the pattern's 0.9", got)
+	}
+	if got := m.Score(context.Background(), "a cold drink"); got != 0.3 {
+		t.Errorf("Score = %v, want the keyword's 0.3", got)
+	}
+}
+
+func TestIntent(t *testing.T) {
+	m := skill.Intent("weather", skill.Keywords(1, "weather"))
+	weather := skill.WithClassification(context.Background(), skill.Classification{Intent: "weather"})
+	general := skill.WithClassification(context.Background(), skill.Classification{Intent: skill.General})
+
+	tests := []struct {
+		ctx   context.Context
+		query string
+		want  float64
+	}{
+		{context.Background(), "weather tomorrow", 1},
+		{context.Background(), "do I need sunglasses", 0},
+		{weather, "do I need sunglasses", 1},
+		{general, "what is weather made of", 0},
+	}
+
+	for _, tt := range tests {
+		if got := m.Score(tt.ctx, tt.query); got != tt.want {
+			t.Errorf("Score(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func near(a, b float64) bool {
+	return a-b < 1e-9 && b-a < 1e-9
+}
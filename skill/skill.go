@@ -0,0 +1,155 @@
+package skill
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Skill handles one kind of question, such as the weather. It scores how
+// well a question fits and produces context for the prompt.
+type Skill interface {
+	Name() string
+	// Match scores the question from 0 (unrelated) to 1 (certain).
+	Match(ctx context.Context, query string) float64
+	Context(ctx context.Context, query string) (string, error)
+}
+
+// Answerer is implemented by skills that can reply without the LLM, such
+// as telling the time.
+type Answerer interface {
+	Answer(ctx context.Context, query string) (string, error)
+}
+
+// FollowUper is implemented by skills that asked the user a question, such
+// as which Paris they meant. FollowUp gets the next question first and
+// reports whether it was the awaited answer.
+type FollowUper interface {
+	FollowUp(ctx context.Context, query string) (string, bool)
+}
+
+// Result is what the registry produced for a question. Skill is empty
+// when no skill applies.
+type Result struct {
+	Skill   string
+	Context string
+	// Answer, when set, is the complete reply and the LLM is not needed.
+	Answer string
+}
+
+// Registry routes each question to the registered skill that fits best.
+type Registry struct {
+	// Threshold is the lowest score that selects a skill.
+	Threshold float64
+	// Classifier, when set, reads each question before the skills match
+	// it. Readings below MinConfidence, and classifier errors, are ignored
+	// and the skills fall back to their other matchers.
+	Classifier    Classifier
+	MinConfidence float64
+	// OnClassify, when set, is called with every reading and error.
+	OnClassify func(Classification, error)
+	// ToolCalling is set when the model is given the skills' tools. Skills
+	// that are tools then leave fetching their data to the model.
+	ToolCalling bool
+
+	mu     sync.Mutex
+	skills []Skill
+}
+
+func NewRegistry() *Registry {
+	return &Registry{Threshold: 0.5, MinConfidence: 0.6}
+}
+
+// Register adds a skill. Skills registered first win ties.
+func (r *Registry) Register(s Skill) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.skills = append(r.skills, s)
+}
+
+func (r *Registry) Skills() []Skill {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Skill(nil), r.skills...)
+}
+
+// Match returns the best-scoring skill above the threshold, or nil.
+func (r *Registry) Match(ctx context.Context, query string) (Skill, float64) {
+	var best Skill
+	var bestScore float64
+
+	for _, s := range r.Skills() {
+		if score := s.Match(ctx, query); score > bestScore {
+			best, bestScore = s, score
+		}
+	}
+
+	if bestScore < r.Threshold {
+		return nil, bestScore
+	}
+	return best, bestScore
+}
+
+// Handle lets a skill that is waiting for an answer look at the question
+// first, then asks the best match for a direct answer or for context.
+// With tool calling, a matching tool adds no context.
+func (r *Registry) Handle(ctx context.Context, query string) (Result, error) {
+	for _, s := range r.Skills() {
+		if f, ok := s.(FollowUper); ok {
+			if context, ok := f.FollowUp(ctx, query); ok {
+				return Result{Skill: s.Name(), Context: context}, nil
+			}
+		}
+	}
+
+	ctx = r.classify(ctx, query)
+
+	s, _ := r.Match(ctx, query)
+	if s == nil {
+		return Result{}, nil
+	}
+
+	if a, ok := s.(Answerer); ok {
+		answer, err := a.Answer(ctx, query)
+		if err != nil {
+			return Result{}, fmt.Errorf("%s: %w", s.Name(), err)
+		}
+		if answer != "" {
+			return Result{Skill: s.Name(), Answer: answer}, nil
+		}
+	}
+
+	if _, ok := s.(Tool); ok && r.ToolCalling {
+		return Result{Skill: s.Name()}, nil
+	}
+
+	context, err := s.Context(ctx, query)
+	if err != nil {
+		return Result{}, fmt.Errorf("%s: %w", s.Name(), err)
+	}
+
+	return Result{Skill: s.Name(), Context: context}, nil
+}
+
+// classify adds a confident classification of the question to ctx.
+func (r *Registry) classify(ctx context.Context, query string) context.Context {
+	if r.Classifier == nil {
+		return ctx
+	}
+
+	intents := []IntentInfo{{Name: General, Description: "anything else, including small talk and general knowledge"}}
+	for _, s := range r.Skills() {
+		if d, ok := s.(Describer); ok {
+			intents = append(intents, IntentInfo{Name: s.Name(), Description: d.Description()})
+		}
+	}
+
+	c, err := r.Classifier.Classify(ctx, query, intents)
+	if r.OnClassify != nil {
+		r.OnClassify(c, err)
+	}
+	if err != nil || c.Confidence < r.MinConfidence {
+		return ctx
+	}
+	return WithClassification(ctx, c)
+}
@@ -0,0 +1,175 @@
+package skill_test
+
+import (
+	"KevinGo/ollama"
+	"KevinGo/skill"
+	"context"
+	"errors"
+	"testing"
+)
+
+// fake is a skill that adds its name as context.
+type fake struct {
+	name    string
+	matcher skill.Matcher
+}
+
+func (f *fake) Name() string { return f.name }
+
+func (f *fake) Match(ctx context.Context, query string) float64 {
+	return f.matcher.Score(ctx, query)
+}
+
+func (f *fake) Context(context.Context, string) (string, error) {
+	return f.name + " context", nil
+}
+
+func (f *fake) Description() string { return f.name + " questions" }
+
+// answering replies without the LLM.
+type answering struct{ fake }
+
+func (a *answering) Answer(context.Context, string) (string, error) {
+	return "It's 3 o'clock.", nil
+}
+
+// tool is a skill the model can call.
+type tool struct{ fake }
+
+func (t *tool) Definitions() []ollama.Tool { return nil }
+
+func (t *tool) Call(context.Context, ollama.ToolCall) (string, error) { return "", nil }
+
+// waiting takes the next question as the answer to its follow-up.
+type waiting struct{ fake }
+
+func (w *waiting) FollowUp(_ context.Context, query string) (string, bool) {
+	return "follow-up to " + query, query == "Texas"
+}
+
+func weather() *fake {
+	return &fake{name: "weather", matcher: skill.Intent("weather", skill.Any(
+		skill.Keywords(1, "weather", "forecast"),
+		skill.Keywords(0.3, "sun", "hot", "cold", "outside"),
+	))}
+}
+
+func TestHandle(t *testing.T) {
+	tests := []struct {
+		query string
+		want  skill.Result
+	}{
+		{"What's the weather in Cluj?", skill.Result{Skill: "weather", Context: "weather context"}},
+		{"Is it hot outside?", skill.Result{Skill: "weather", Context: "weather context"}},
+		// One weak keyword is not enough.
+		{"I have a cold", skill.Result{}},
+		// Neither counts as "sun" or "hot".
+		{"What are your Sunday plans?", skill.Result{}},
+		{"Why is he so hot-headed?", skill.Result{}},
+		{"What time is it?", skill.Result{Skill: "clock", Answer: "It's 3 o'clock."}},
+	}
+
+	r := skill.NewRegistry()
+	r.Register(weather())
+	r.Register(&answering{fake{name: "clock", matcher: skill.Keywords(1, "time")}})
+
+	for _, tt := range tests {
+		got, err := r.Handle(context.Background(), tt.query)
+		if err != nil {
+			t.Fatalf("Handle(%q): %v", tt.query, err)
+		}
+		if got != tt.want {
+			t.Errorf("Handle(%q) = %+v, want %+v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestMatchTies(t *testing.T) {
+	r := skill.NewRegistry()
+	r.Register(weather())
+	r.Register(&fake{name: "clothes", matcher: skill.Keywords(1, "weather", "jacket")})
+
+	s, score := r.Match(context.Background(), "what weather needs a jacket")
+	if s == nil || s.Name() != "weather" || score != 1 {
+		t.Errorf("Match = %v, %v; want weather, registered first, to win the tie", s, score)
+	}
+
+	s, _ = r.Match(context.Background(), "which jacket")
+	if s == nil || s.Name() != "clothes" {
+		t.Errorf("Match = %v, want the only skill that scores", s)
+	}
+}
+
+func TestHandleToolCalling(t *testing.T) {
+	r := skill.NewRegistry()
+	r.Register(&tool{*weather()})
+	r.ToolCalling = true
+
+	got, err := r.Handle(context.Background(), "weather tomorrow")
+	if err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if want := (skill.Result{Skill: "weather"}); got != want {
+		t.Errorf("Handle = %+v, want %+v without context", got, want)
+	}
+}
+
+func TestHandleFollowUp(t *testing.T) {
+	r := skill.NewRegistry()
+	r.Register(weather())
+	r.Register(&waiting{fake{name: "places", matcher: skill.Keywords(1, "place")}})
+
+	got, _ := r.Handle(context.Background(), "Texas")
+	if want := (skill.Result{Skill: "places", Context: "follow-up to Texas"}); got != want {
+		t.Errorf("Handle = %+v, want %+v", got, want)
+	}
+
+	got, _ = r.Handle(context.Background(), "weather in Paris")
+	if got.Skill != "weather" {
+		t.Errorf("Handle = %+v, want the weather skill once the follow-up declines", got)
+	}
+}
+
+// classifier returns a fixed reading.
+type classifier struct {
+	c   skill.Classification
+	err error
+}
+
+func (c classifier) Classify(context.Context, string, []skill.IntentInfo) (skill.Classification, error) {
+	return c.c, c.err
+}
+
+func TestHandleClassifier(t *testing.T) {
+	tests := []struct {
+		name       string
+		classifier classifier
+		query      string
+		want       string
+	}{
+		{"confident", classifier{c: skill.Classification{Intent: "weather", Confidence: 0.9}}, "do I need sunglasses", "weather"},
+		{"confident general", classifier{c: skill.Classification{Intent: skill.General, Confidence: 0.9}}, "weather is a funny word", ""},
+		{"unsure", classifier{c: skill.Classification{Intent: skill.General, Confidence: 0.3}}, "weather tomorrow", "weather"},
+		{"failed", classifier{err: errors.New("timeout")}, "weather tomorrow", "weather"},
+	}
+
+	for _, tt := range tests {
+		r := skill.NewRegistry()
+		r.Register(weather())
+		r.Classifier = tt.classifier
+
+		var seen int
+		r.OnClassify = func(skill.Classification, error) { seen++ }
+
+		got, err := r.Handle(context.Background(), tt.query)
+		if err != nil {
+			t.Fatalf("%s: Handle: %v", tt.name, err)
+		}
+		if got.Skill != tt.want {
+			t.Errorf("%s: skill = %q, want %q", tt.name, got.Skill, tt.want)
+		}
+		if seen != 1 {
+			t.Errorf("%s: OnClassify called %d times, want once", tt.name, seen)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package skill
+
+import (
+	"KevinGo/ollama"
+	"context"
+	"fmt"
+)
+
+// Tool is implemented by skills the model can call itself, with arguments
+// it picked out of the question, instead of having their context added to
+// every prompt.
+type Tool interface {
+	Definitions() []ollama.Tool
+	// Call runs one of the skill's tools and returns the result for the
+	// model.
+	Call(ctx context.Context, call ollama.ToolCall) (string, error)
+}
+
+// Tools lists the tools of every registered skill.
+func (r *Registry) Tools() []ollama.Tool {
+	var tools []ollama.Tool
+	for _, s := range r.Skills() {
+		if t, ok := s.(Tool); ok {
+			tools = append(tools, t.Definitions()...)
+		}
+	}
+	return tools
+}
+
+// Call runs the tool the model asked for.
+func (r *Registry) Call(ctx context.Context, call ollama.ToolCall) (string, error) {
+	for _, s := range r.Skills() {
+		t, ok := s.(Tool)
+		if !ok {
+			continue
+		}
+		for _, definition := range t.Definitions() {
+			if definition.Function.Name == call.Function.Name {
+				return t.Call(ctx, call)
+			}
+		}
+	}
+	return "", fmt.Errorf("unknown tool %q", call.Function.Name)
+}
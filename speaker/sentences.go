@@ -0,0 +1,151 @@
+package speaker
+
+import (
+	"strings"
+	"unicode"
+)
+
+// abbreviations end with a period without ending the sentence.
+var abbreviations = map[string]bool{
+	"mr": true, "mrs": true, "ms": true, "dr": true, "prof": true, "st": true,
+	"vs": true, "etc": true, "e.g": true, "i.e": true, "approx": true,
+}
+
+// Splitter cuts streamed text into sentences as soon as each one is
+// complete, so it can be synthesized while the rest is still generated.
+type Splitter struct {
+	// MinLength keeps very short fragments such as "Sure." attached to the
+	// following sentence, which sounds more natural than a separate clip.
+	MinLength int
+
+	buf strings.Builder
+}
+
+// Push adds a chunk of text and returns the sentences it completed.
+func (s *Splitter) Push(chunk string) []string {
+	s.buf.WriteString(chunk)
+	text := s.buf.String()
+
+	var sentences []string
+	runes := []rune(text)
+	start, offset := 0, 0
+
+	for i, r := range runes {
+		size := len(string(r))
+		if i+1 < len(runes) && isBoundary(runes, i) {
+			end := offset + size
+			sentence := strings.TrimSpace(text[start:end])
+			if sentence == "" {
+				start = end
+			} else if len(sentence) >= s.MinLength {
+				sentences = append(sentences, sentence)
+				start = end
+			}
+		}
+		offset += size
+	}
+
+	s.buf.Reset()
+	s.buf.WriteString(text[start:])
+	return sentences
+}
+
+// Flush returns whatever text is left once the stream has ended.
+func (s *Splitter) Flush() string {
+	rest := strings.TrimSpace(s.buf.String())
+	s.buf.Reset()
+	return rest
+}
+
+// isBoundary reports whether runes[i] ends a sentence. The following rune
+// must be known, so a period at the end of a chunk waits for the next one.
+func isBoundary(runes []rune, i int) bool {
+	r, next := runes[i], runes[i+1]
+
+	if r == '\n' {
+		return true
+	}
+	if !strings.ContainsRune(".!?…", r) || !unicode.IsSpace(next) {
+		return false
+	}
+	if r != '.' {
+		return true
+	}
+
+	// "Dr. Smith" and "J. R. R. Tolkien" do not end a sentence, but "so
+	// do I. Then" does: a capital is only an initial next to another one.
+	j := i
+	for j > 0 && !unicode.IsSpace(runes[j-1]) {
+		j--
+	}
+	word := strings.TrimLeft(string(runes[j:i+1]), "(\"'")
+	if abbreviations[strings.ToLower(strings.TrimSuffix(word, "."))] {
+		return false
+	}
+	if isInitial(word) {
+		next, complete := wordAfter(runes, i+1)
+		if !complete {
+			// Wait for the next word to tell.
+			return false
+		}
+		return !isInitial(wordBefore(runes, j)) && !isInitial(next)
+	}
+
+	return true
+}
+
+// isInitial reports whether word is a capital and a period, as in "R.".
+func isInitial(word string) bool {
+	r := []rune(word)
+	return len(r) == 2 && unicode.IsUpper(r[0]) && r[1] == '.'
+}
+
+// wordBefore returns the word that ends before runes[end].
+func wordBefore(runes []rune, end int) string {
+	for end > 0 && unicode.IsSpace(runes[end-1]) {
+		end--
+	}
+	start := end
+	for start > 0 && !unicode.IsSpace(runes[start-1]) {
+		start--
+	}
+	return string(runes[start:end])
+}
+
+// wordAfter returns the word that starts after runes[start] and whether
+// it is complete, rather than cut off by the end of the text so far.
+func wordAfter(runes []rune, start int) (string, bool) {
+	for start < len(runes) && unicode.IsSpace(runes[start]) {
+		start++
+	}
+	end := start
+	for end < len(runes) && !unicode.IsSpace(runes[end]) {
+		end++
+	}
+	return string(runes[start:end]), end < len(runes)
+}
+
+// ForSpeech removes markdown and emoji that TTS engines would read aloud
+// or choke on.
+func ForSpeech(text string) string {
+	var b strings.Builder
+	prev := ' '
+	for _, r := range text {
+		switch {
+		case r == '#' && (unicode.IsLetter(prev) || unicode.IsDigit(prev)):
+			// "C#" is a name; "## Heading" is markup.
+		case strings.ContainsRune("*_#`~|>", r):
+			continue
+		case r > 0x2100 && (unicode.Is(unicode.So, r) || unicode.Is(unicode.Sk, r)):
+			continue
+		case r == 0xFE0F || r == 0x200D:
+			continue
+		}
+		b.WriteRune(r)
+		prev = r
+	}
+	text = b.String()
+
+	// List bullets are dropped too, numbers are worth reading.
+	return strings.TrimLeft(strings.Join(strings.Fields(text), " "), "-• ")
+}
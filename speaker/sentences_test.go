@@ -0,0 +1,103 @@
+package speaker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitter(t *testing.T) {
+	tests := []struct {
+		name      string
+		chunks    []string
+		minLength int
+		want      []string
+	}{
+		{
+			name:   "whole sentences",
+			chunks: []string{"It's sunny. Take sunglasses! Any questions? "},
+			want:   []string{"It's sunny.", "Take sunglasses!", "Any questions?"},
+		},
+		{
+			name:   "split across chunks",
+			chunks: []string{"It's 14", " degrees", ".", " Light", " rain", " later", "."},
+			want:   []string{"It's 14 degrees.", "Light rain later."},
+		},
+		{
+			name:   "decimal point",
+			chunks: []string{"It's 14.5 degrees. Nice."},
+			want:   []string{"It's 14.5 degrees.", "Nice."},
+		},
+		{
+			name:   "abbreviations",
+			chunks: []string{"Ask Dr. Smith, e.g. tomorrow. Or not."},
+			want:   []string{"Ask Dr. Smith, e.g. tomorrow.", "Or not."},
+		},
+		{
+			name:   "no is a word",
+			chunks: []string{"The answer is no. Next question."},
+			want:   []string{"The answer is no.", "Next question."},
+		},
+		{
+			name:   "initials",
+			chunks: []string{"It was written by J. R. R. Tolkien. He was English."},
+			want:   []string{"It was written by J. R. R. Tolkien.", "He was English."},
+		},
+		{
+			name:   "initials across chunks",
+			chunks: []string{"By J.", " R", ". R", ". Tolkien", ". Yes."},
+			want:   []string{"By J. R. R. Tolkien.", "Yes."},
+		},
+		{
+			name:   "I ends a sentence",
+			chunks: []string{"You like rain, and so do I. Then take a walk."},
+			want:   []string{"You like rain, and so do I.", "Then take a walk."},
+		},
+		{
+			name:   "new lines",
+			chunks: []string{"Today:\n- sunny\n", "- 20 degrees\n"},
+			want:   []string{"Today:", "- sunny", "- 20 degrees"},
+		},
+		{
+			name:      "short fragments join the next sentence",
+			chunks:    []string{"Sure. ", "It's 20 degrees", " in Cluj. Ok. Bye. "},
+			minLength: 10,
+			want:      []string{"Sure. It's 20 degrees in Cluj.", "Ok. Bye."},
+		},
+	}
+
+	for _, tt := range tests {
+		s := &Splitter{MinLength: tt.minLength}
+		var got []string
+		for _, chunk := range tt.chunks {
+			got = append(got, s.Push(chunk)...)
+		}
+		if rest := s.Flush(); rest != "" {
+			got = append(got, rest)
+		}
+
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestForSpeech(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"**Sunny** and _warm_ ☀️", "Sunny and warm"},
+		{"## Forecast", "Forecast"},
+		{"- take a `jacket`", "take a jacket"},
+		{"• 20 degrees", "20 degrees"},
+		{"Written in C# and F#.", "Written in C# and F#."},
+		{"Item #3", "Item 3"},
+		{"> quoted | table", "quoted table"},
+	}
+
+	for _, tt := range tests {
+		if got := ForSpeech(tt.text); got != tt.want {
+			t.Errorf("ForSpeech(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
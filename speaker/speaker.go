@@ -0,0 +1,235 @@
+package speaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"KevinGo/playback"
+	"KevinGo/tts"
+)
+
+// Options configures a Speaker.
+type Options struct {
+	Synthesizers tts.Chain
+	// OnAttempt is told about every TTS backend that was tried.
+	OnAttempt tts.AttemptFunc
+	// Fallback plays a clip the native player could not decode or play,
+	// for example through an external program. Once native playback has
+	// failed, every remaining clip goes through Fallback.
+	Fallback func(ctx context.Context, audio *tts.Audio) error
+}
+
+type clip struct {
+	text  string
+	audio *tts.Audio
+}
+
+// Speaker speaks a response while it is still being generated: text is
+// split into sentences, each one is synthesized as soon as it is complete
+// and the clips are played back to back in order.
+type Speaker struct {
+	opts     Options
+	ctx      context.Context
+	cancel   context.CancelFunc
+	splitter Splitter
+
+	sentences chan string
+	clips     chan clip
+	done      chan struct{}
+
+	mu      sync.Mutex
+	err     error
+	stopped bool
+	spoken  []string
+	current string
+	heard   float64
+}
+
+// New starts the synthesis and playback workers. Cancelling ctx stops
+// both right away.
+func New(ctx context.Context, opts Options) *Speaker {
+	ctx, cancel := context.WithCancel(ctx)
+	s := &Speaker{
+		opts:      opts,
+		ctx:       ctx,
+		cancel:    cancel,
+		splitter:  Splitter{MinLength: 12},
+		sentences: make(chan string, 256),
+		clips:     make(chan clip, 2),
+		done:      make(chan struct{}),
+	}
+
+	go s.synthesize()
+	go s.play()
+	return s
+}
+
+// Write feeds a chunk of the streamed response.
+func (s *Speaker) Write(chunk string) {
+	for _, sentence := range s.splitter.Push(chunk) {
+		s.enqueue(sentence)
+	}
+}
+
+// Close speaks whatever is left and waits until everything has been heard.
+// It returns the first synthesis or playback error.
+func (s *Speaker) Close() error {
+	if rest := s.splitter.Flush(); rest != "" {
+		s.enqueue(rest)
+	}
+	close(s.sentences)
+
+	<-s.done
+	s.cancel()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped && errors.Is(s.err, context.Canceled) {
+		return nil
+	}
+	return s.err
+}
+
+// Stop cuts playback off right away, for example when the user starts
+// talking over the answer. Close must still be called.
+func (s *Speaker) Stop() {
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+	s.cancel()
+}
+
+// Spoken returns the part of the response that has been played so far.
+// The sentence that was playing when Stop was called is cut at the word
+// that was being said, estimated from how much of its clip was heard.
+func (s *Speaker) Spoken() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	spoken := append([]string(nil), s.spoken...)
+	if words := strings.Fields(s.current); len(words) > 0 {
+		if n := int(float64(len(words)) * s.heard); n > 0 {
+			spoken = append(spoken, strings.Join(words[:n], " "))
+		}
+	}
+
+	return strings.Join(spoken, " ")
+}
+
+func (s *Speaker) playing(text string, heard float64) {
+	s.mu.Lock()
+	s.current, s.heard = text, heard
+	s.mu.Unlock()
+}
+
+func (s *Speaker) played(text string) {
+	s.mu.Lock()
+	s.spoken = append(s.spoken, text)
+	s.current, s.heard = "", 0
+	s.mu.Unlock()
+}
+
+func (s *Speaker) enqueue(sentence string) {
+	if sentence = ForSpeech(sentence); sentence == "" {
+		return
+	}
+
+	select {
+	case s.sentences <- sentence:
+	case <-s.ctx.Done():
+	}
+}
+
+func (s *Speaker) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+func (s *Speaker) synthesize() {
+	defer close(s.clips)
+
+	for sentence := range s.sentences {
+		if s.ctx.Err() != nil {
+			continue
+		}
+
+		audio, _, err := s.opts.Synthesizers.Synthesize(s.ctx, sentence, s.opts.OnAttempt)
+		if err != nil {
+			s.fail(fmt.Errorf("could not synthesize %q: %w", sentence, err))
+			continue
+		}
+
+		select {
+		case s.clips <- clip{text: sentence, audio: audio}:
+		case <-s.ctx.Done():
+		}
+	}
+}
+
+func (s *Speaker) play() {
+	defer close(s.done)
+
+	stream := playback.OpenStream()
+	defer stream.Abort()
+	native := true
+
+	for c := range s.clips {
+		if s.ctx.Err() != nil {
+			continue
+		}
+
+		if native {
+			err := s.playNative(stream, c)
+			if err == nil {
+				s.played(c.text)
+				continue
+			}
+			if s.ctx.Err() != nil {
+				continue
+			}
+			if s.opts.Fallback == nil {
+				s.fail(err)
+				continue
+			}
+
+			native = false
+			stream.Abort()
+		}
+
+		s.playing(c.text, 0)
+		if err := s.opts.Fallback(s.ctx, c.audio); err == nil {
+			s.played(c.text)
+		} else if s.ctx.Err() == nil {
+			s.fail(err)
+		}
+	}
+
+	if native {
+		if err := stream.Close(s.ctx); err != nil && !errors.Is(err, context.Canceled) {
+			s.fail(err)
+		}
+	}
+
+	if err := s.ctx.Err(); err != nil {
+		s.fail(err)
+	}
+}
+
+func (s *Speaker) playNative(stream *playback.Stream, c clip) error {
+	pcm, err := playback.Decode(c.audio.Data)
+	if err != nil {
+		return err
+	}
+
+	return stream.Write(s.ctx, pcm, func(progress playback.Progress) {
+		if progress.Total > 0 {
+			s.playing(c.text, float64(progress.Played)/float64(progress.Total))
+		}
+	})
+}
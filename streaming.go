@@ -0,0 +1,116 @@
+package main
+
+import (
+	"KevinGo/stt"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+func newStreamer() stt.Streamer {
+	if !cfg.STT.Streaming {
+		return nil
+	}
+	return stt.AssemblyAIStreaming{
+		URL:    cfg.AssemblyAI.StreamingURL,
+		APIKey: cfg.AssemblyAI.APIKey,
+	}
+}
+
+// streamRequest captures a request with voice activity detection while
+// every frame is sent to the streamer as it is captured, and shows the
+// transcript so far while the user talks. When in is set, it continues
+// the request that interrupted the last answer.
+func streamRequest(streamer stt.Streamer, in *interruption) (string, error) {
+	var mic *microphone
+	var seed []int16
+	var patience time.Duration
+	if in != nil {
+		mic, seed, patience = in.mic, in.seed, interruptPatience
+	}
+	if mic == nil {
+		var err error
+		if mic, err = openMicrophone(512); err != nil {
+			return "", fmt.Errorf("Recording error: %w", err)
+		}
+	}
+	defer mic.Close()
+
+	partial := &partialLine{w: status}
+	stream, err := streamer.Start(context.Background(), cfg.Audio.SampleRate, partial.show)
+	if err != nil {
+		return "", fmt.Errorf("Could not start streaming transcription: %w", err)
+	}
+	defer stream.Close()
+
+	// A stream that failed reports why from Finish, so send errors are
+	// not checked while capturing.
+	stream.Send(seed)
+
+	frames := make(chan []int16)
+	stop := make(chan struct{})
+	go func() {
+		defer close(frames)
+		for frame := range mic.frames {
+			stream.Send(frame)
+			select {
+			case frames <- frame:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	samples, err := captureUtterance(frames, seed, patience)
+	close(stop)
+	if err != nil {
+		partial.end()
+		if errors.Is(err, errNothingHeard) {
+			return "", err
+		}
+		return "", fmt.Errorf("Recording error: %w", err)
+	}
+
+	archiveRecording(recording(samples))
+
+	text, err := stream.Finish()
+	partial.end()
+	if err != nil {
+		return "", fmt.Errorf("Could not transcribe audio: %w", err)
+	}
+	return text, nil
+}
+
+// partialLine shows the transcript so far on a single line that is
+// rewritten as results arrive.
+type partialLine struct {
+	w     io.Writer
+	mu    sync.Mutex
+	shown bool
+	ended bool
+}
+
+func (p *partialLine) show(text string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.ended || text == "" {
+		return
+	}
+	fmt.Fprintf(p.w, "\r\033[K📝 %s", lastWords(text, 12))
+	p.shown = true
+}
+
+// end moves past the partial line; results arriving later are not shown.
+func (p *partialLine) end() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.shown {
+		fmt.Fprintln(p.w)
+	}
+	p.ended = true
+}
@@ -0,0 +1,86 @@
+package stt
+
+import (
+	"KevinGo/audio"
+	"KevinGo/poll"
+	"KevinGo/transcribe"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"time"
+)
+
+// AssemblyAI uploads the recording to AssemblyAI and polls for the result.
+type AssemblyAI struct {
+	APIKey string
+	// Format is the upload encoding: "flac" (the default) or "wav" are
+	// encoded in-process at 16 kHz mono while the upload runs; "m4a" is
+	// converted with ffmpeg.
+	Format string
+	// UploadTimeout bounds sending the audio and starting the transcript.
+	// It defaults to one minute.
+	UploadTimeout time.Duration
+	Poll          poll.Options
+}
+
+func (AssemblyAI) Name() string {
+	return "AssemblyAI"
+}
+
+func (a AssemblyAI) Transcribe(ctx context.Context, rec audio.Recording) (string, error) {
+	body, contentType, err := a.encode(ctx, rec)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	uploadTimeout := a.UploadTimeout
+	if uploadTimeout <= 0 {
+		uploadTimeout = time.Minute
+	}
+	uploadCtx, cancel := context.WithTimeout(ctx, uploadTimeout)
+	transcriptID, err := transcribe.Transcribe(uploadCtx, a.APIKey, body, contentType)
+	cancel()
+	if err != nil {
+		return "", err
+	}
+
+	opts := a.Poll
+	opts.APIKey = a.APIKey
+	return poll.StartPolling(ctx, transcriptID, opts)
+}
+
+// encode returns the recording in the upload format with its content
+// type. Nothing is written to disk.
+func (a AssemblyAI) encode(ctx context.Context, rec audio.Recording) (io.ReadCloser, string, error) {
+	if a.Format == "m4a" {
+		wav, err := rec.Encode(audio.WAV)
+		if err != nil {
+			return nil, "", err
+		}
+
+		// A fragmented MP4 can be written to a pipe, which a plain one
+		// cannot because its index is written last.
+		var m4a bytes.Buffer
+		cmd := exec.CommandContext(ctx, "ffmpeg", "-y", "-f", "wav", "-i", "pipe:0",
+			"-c:a", "aac", "-movflags", "frag_keyframe+empty_moov", "-f", "mp4", "pipe:1")
+		cmd.Stdin = bytes.NewReader(wav)
+		cmd.Stdout = &m4a
+		if err := cmd.Run(); err != nil {
+			return nil, "", fmt.Errorf("conversion error: %w", err)
+		}
+		return io.NopCloser(&m4a), audio.ContentType(".m4a"), nil
+	}
+
+	format := audio.FLAC
+	if a.Format != "" {
+		var err error
+		if format, err = audio.ParseFormat(a.Format); err != nil {
+			return nil, "", err
+		}
+	}
+
+	return rec.ForSpeech().Stream(format), format.ContentType(), nil
+}
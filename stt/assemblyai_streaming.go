@@ -0,0 +1,303 @@
+package stt
+
+import (
+	"KevinGo/apierror"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// DefaultStreamingURL is AssemblyAI's real-time transcription endpoint.
+const DefaultStreamingURL = "wss://streaming.assemblyai.com/v3/ws"
+
+// AssemblyAIStreaming sends the audio to AssemblyAI's real-time API over a
+// WebSocket as it is captured and receives partial and final transcripts
+// back.
+type AssemblyAIStreaming struct {
+	// URL defaults to DefaultStreamingURL.
+	URL    string
+	APIKey string
+	// FinishTimeout bounds the wait for the final transcript once the
+	// audio has ended. It defaults to 5 seconds.
+	FinishTimeout time.Duration
+}
+
+func (AssemblyAIStreaming) Name() string {
+	return "AssemblyAI streaming"
+}
+
+// streamMessage is any message of the real-time API. Turn messages carry
+// the transcript of one turn, first as it is heard and then once more,
+// formatted, when the turn has ended; Begin and Termination carry none.
+type streamMessage struct {
+	Type       string `json:"type"`
+	TurnOrder  int    `json:"turn_order"`
+	Transcript string `json:"transcript"`
+	Error      string `json:"error"`
+}
+
+func (a AssemblyAIStreaming) Start(ctx context.Context, sampleRate int, onPartial func(string)) (Stream, error) {
+	endpoint := a.URL
+	if endpoint == "" {
+		endpoint = DefaultStreamingURL
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid streaming URL: %w", err)
+	}
+	query := u.Query()
+	query.Set("sample_rate", strconv.Itoa(sampleRate))
+	query.Set("encoding", "pcm_s16le")
+	query.Set("format_turns", "true")
+	u.RawQuery = query.Encode()
+
+	header := http.Header{}
+	header.Set("Authorization", a.APIKey)
+
+	conn, res, err := websocket.DefaultDialer.DialContext(ctx, u.String(), header)
+	if err != nil {
+		if res != nil {
+			if statusErr := apierror.Check("AssemblyAI streaming", res); statusErr != nil {
+				return nil, statusErr
+			}
+		}
+		return nil, apierror.Transport("AssemblyAI streaming", err)
+	}
+
+	finishTimeout := a.FinishTimeout
+	if finishTimeout <= 0 {
+		finishTimeout = 5 * time.Second
+	}
+
+	s := &assemblyAIStream{
+		conn:          conn,
+		onPartial:     onPartial,
+		finishTimeout: finishTimeout,
+		// The API takes between 50 ms and 1 s of audio per message.
+		chunk:   sampleRate / 10,
+		minimum: sampleRate / 20,
+		audio:   make(chan []byte, 256),
+		turns:   map[int]string{},
+		done:    make(chan struct{}),
+	}
+	go s.write()
+	go s.read()
+
+	return s, nil
+}
+
+type assemblyAIStream struct {
+	conn          *websocket.Conn
+	onPartial     func(string)
+	finishTimeout time.Duration
+	chunk         int
+	minimum       int
+
+	mu      sync.Mutex
+	pending []int16
+	closed  bool
+	audio   chan []byte
+
+	// turns is updated by read as results arrive. err is written by read
+	// and only looked at by others once done is closed.
+	turnsMu sync.Mutex
+	turns   map[int]string
+	err     error
+	done    chan struct{}
+}
+
+func (s *assemblyAIStream) Send(samples []int16) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrStreamClosed
+	}
+
+	s.pending = append(s.pending, samples...)
+	for len(s.pending) >= s.chunk {
+		if err := s.queue(s.pending[:s.chunk]); err != nil {
+			return err
+		}
+		s.pending = s.pending[s.chunk:]
+	}
+	return nil
+}
+
+// queue hands a chunk to the writer. It must be called with mu held.
+func (s *assemblyAIStream) queue(samples []int16) error {
+	data := make([]byte, len(samples)*2)
+	for i, sample := range samples {
+		binary.LittleEndian.PutUint16(data[i*2:], uint16(sample))
+	}
+
+	select {
+	case s.audio <- data:
+		return nil
+	case <-s.done:
+		return s.failure()
+	}
+}
+
+func (s *assemblyAIStream) Finish() (string, error) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return "", ErrStreamClosed
+	}
+	var err error
+	if len(s.pending) > 0 {
+		// The last chunk is padded with silence up to the shortest one
+		// the API accepts.
+		last := append(s.pending, make([]int16, max(s.minimum-len(s.pending), 0))...)
+		err = s.queue(last)
+		s.pending = nil
+	}
+	s.closed = true
+	close(s.audio)
+	s.mu.Unlock()
+
+	if err != nil {
+		s.conn.Close()
+		return "", err
+	}
+
+	select {
+	case <-s.done:
+	case <-time.After(s.finishTimeout):
+		s.conn.Close()
+		<-s.done
+		if text := s.transcript(); text != "" {
+			return text, nil
+		}
+		return "", fmt.Errorf("AssemblyAI streaming did not finish in time")
+	}
+
+	if s.err != nil {
+		return "", s.err
+	}
+	text := s.transcript()
+	if text == "" {
+		return "", ErrNoSpeech
+	}
+	return text, nil
+}
+
+func (s *assemblyAIStream) Close() error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.audio)
+	}
+	s.mu.Unlock()
+
+	return s.conn.Close()
+}
+
+// write sends the queued audio and, once the queue is closed, asks the
+// server to finish the last turn and end the session.
+func (s *assemblyAIStream) write() {
+	for data := range s.audio {
+		if err := s.conn.WriteMessage(websocket.BinaryMessage, data); err != nil {
+			// Reading fails too and reports why; drain what is left so
+			// senders do not block.
+			for range s.audio {
+			}
+			return
+		}
+	}
+	s.conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"Terminate"}`))
+}
+
+func (s *assemblyAIStream) read() {
+	defer close(s.done)
+
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			var closeErr *websocket.CloseError
+			if errors.As(err, &closeErr) && closeErr.Code != websocket.CloseNormalClosure {
+				s.err = closeError(closeErr)
+			} else if !errors.As(err, &closeErr) {
+				s.err = fmt.Errorf("AssemblyAI streaming connection error: %w", err)
+			}
+			return
+		}
+
+		var msg streamMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			continue
+		}
+
+		switch {
+		case msg.Error != "":
+			s.err = fmt.Errorf("AssemblyAI streaming error: %s", msg.Error)
+			return
+		case msg.Type == "Turn":
+			s.turnsMu.Lock()
+			s.turns[msg.TurnOrder] = msg.Transcript
+			s.turnsMu.Unlock()
+			if s.onPartial != nil {
+				s.onPartial(s.transcript())
+			}
+		case msg.Type == "Termination":
+			return
+		}
+	}
+}
+
+// closeCodes classifies the codes AssemblyAI ends a session with.
+var closeCodes = map[int]error{
+	websocket.ClosePolicyViolation: apierror.ErrAuth,        // unauthorized connection
+	4001:                           apierror.ErrAuth,        // not authorized
+	4002:                           apierror.ErrRateLimited, // insufficient balance
+	4003:                           apierror.ErrAuth,        // not available on this account
+	4029:                           apierror.ErrRateLimited, // too many sessions
+}
+
+func closeError(closeErr *websocket.CloseError) error {
+	if sentinel, ok := closeCodes[closeErr.Code]; ok {
+		return fmt.Errorf("AssemblyAI streaming error %d: %s: %w", closeErr.Code, closeErr.Text, sentinel)
+	}
+	return fmt.Errorf("AssemblyAI streaming error %d: %s", closeErr.Code, closeErr.Text)
+}
+
+// failure is the reason the connection ended early.
+func (s *assemblyAIStream) failure() error {
+	if s.err != nil {
+		return s.err
+	}
+	return ErrStreamClosed
+}
+
+// transcript joins the turns heard so far. A formatted turn replaces the
+// unformatted one with the same order.
+func (s *assemblyAIStream) transcript() string {
+	s.turnsMu.Lock()
+	defer s.turnsMu.Unlock()
+
+	orders := make([]int, 0, len(s.turns))
+	for order := range s.turns {
+		orders = append(orders, order)
+	}
+	sort.Ints(orders)
+
+	var parts []string
+	for _, order := range orders {
+		if text := strings.TrimSpace(s.turns[order]); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,157 @@
+package stt_test
+
+import (
+	"KevinGo/apierror"
+	"KevinGo/stt"
+	"KevinGo/stt/streamtest"
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+const rate = 16000
+
+// collector records the partial transcripts in the order they arrive.
+type collector struct {
+	mu       sync.Mutex
+	partials []string
+}
+
+func (c *collector) add(text string) {
+	c.mu.Lock()
+	c.partials = append(c.partials, text)
+	c.mu.Unlock()
+}
+
+func (c *collector) all() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.partials...)
+}
+
+func start(t *testing.T, srv *streamtest.Server, onPartial func(string)) stt.Stream {
+	t.Helper()
+	streamer := stt.AssemblyAIStreaming{URL: srv.URL(), APIKey: "test"}
+	stream, err := streamer.Start(context.Background(), rate, onPartial)
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	t.Cleanup(func() { stream.Close() })
+	return stream
+}
+
+func TestStreamingPartialsAndFinal(t *testing.T) {
+	srv := streamtest.NewServer("What's the weather in Cluj?")
+	defer srv.Close()
+
+	var c collector
+	stream := start(t, srv, c.add)
+
+	// Five messages of 100 ms, one word back for each.
+	for i := 0; i < 5; i++ {
+		if err := stream.Send(make([]int16, rate/10)); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+
+	text, err := stream.Finish()
+	if err != nil {
+		t.Fatalf("Finish: %v", err)
+	}
+	if text != "What's the weather in Cluj?" {
+		t.Errorf("final = %q, want the formatted turn", text)
+	}
+
+	want := []string{
+		"what's",
+		"what's the",
+		"what's the weather",
+		"what's the weather in",
+		"what's the weather in cluj",
+		"what's the weather in cluj",
+		"What's the weather in Cluj?",
+	}
+	if got := c.all(); !reflect.DeepEqual(got, want) {
+		t.Errorf("partials = %q, want %q", got, want)
+	}
+	if srv.SampleRate() != rate {
+		t.Errorf("sample rate = %d, want %d", srv.SampleRate(), rate)
+	}
+}
+
+func TestStreamingNoSpeech(t *testing.T) {
+	srv := streamtest.NewServer("")
+	defer srv.Close()
+
+	stream := start(t, srv, nil)
+	stream.Send(make([]int16, rate/10))
+
+	if _, err := stream.Finish(); !errors.Is(err, stt.ErrNoSpeech) {
+		t.Errorf("err = %v, want ErrNoSpeech", err)
+	}
+}
+
+func TestStreamingUnauthorized(t *testing.T) {
+	srv := streamtest.NewServer("hello")
+	defer srv.Close()
+
+	streamer := stt.AssemblyAIStreaming{URL: srv.URL()}
+	_, err := streamer.Start(context.Background(), rate, nil)
+	var statusErr *apierror.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != 401 {
+		t.Fatalf("err = %v, want a 401 StatusError", err)
+	}
+	if !errors.Is(err, apierror.ErrAuth) {
+		t.Errorf("err = %v, want it to match ErrAuth", err)
+	}
+}
+
+func TestStreamingClosedByServer(t *testing.T) {
+	tests := []struct {
+		code int
+		want error
+	}{
+		{4001, apierror.ErrAuth},
+		{4002, apierror.ErrRateLimited},
+		{1011, nil},
+	}
+
+	for _, tt := range tests {
+		srv := streamtest.NewServer("hello there")
+		srv.CloseCode, srv.CloseReason = tt.code, "closed by test"
+
+		stream := start(t, srv, nil)
+		stream.Send(make([]int16, rate/10))
+		_, err := stream.Finish()
+		srv.Close()
+
+		if err == nil {
+			t.Errorf("close %d: Finish succeeded, want an error", tt.code)
+			continue
+		}
+		if tt.want != nil && !errors.Is(err, tt.want) {
+			t.Errorf("close %d: err = %v, want %v", tt.code, err, tt.want)
+		}
+	}
+}
+
+// The API rejects messages under 50 ms, so the tail is padded with silence.
+func TestStreamingPadsFinalChunk(t *testing.T) {
+	srv := streamtest.NewServer("hello")
+	defer srv.Close()
+
+	stream := start(t, srv, nil)
+	stream.Send(make([]int16, rate/10+100))
+
+	if _, err := stream.Finish(); err != nil {
+		t.Fatalf("Finish: %v", err)
+	}
+	if srv.Messages() != 2 {
+		t.Errorf("messages = %d, want a full chunk and the tail", srv.Messages())
+	}
+	if want := (rate/10 + rate/20) * 2; srv.AudioBytes() != want {
+		t.Errorf("audio = %d bytes, want %d with the tail padded to 50 ms", srv.AudioBytes(), want)
+	}
+}
@@ -0,0 +1,31 @@
+package stt
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrStreamClosed is returned when audio is sent to a stream that has
+// already finished or been closed.
+var ErrStreamClosed = errors.New("transcription stream closed")
+
+// Streamer transcribes audio while it is being recorded, so the transcript
+// is ready the moment the user stops talking.
+type Streamer interface {
+	Name() string
+	// Start opens a transcription of mono 16-bit audio at sampleRate.
+	// onPartial, which may be nil, is called with the transcript so far
+	// whenever it changes.
+	Start(ctx context.Context, sampleRate int, onPartial func(string)) (Stream, error)
+}
+
+// Stream is one transcription in progress.
+type Stream interface {
+	// Send queues captured samples. It does not wait for the network.
+	Send(samples []int16) error
+	// Finish marks the end of the audio and waits for the final
+	// transcript.
+	Finish() (string, error)
+	// Close abandons the transcription if it has not finished.
+	Close() error
+}
@@ -0,0 +1,178 @@
+// Package streamtest provides a local stand-in for AssemblyAI's real-time
+// transcription WebSocket, so stt.AssemblyAIStreaming and the live partial
+// results can be exercised without network access or an API key.
+//
+//	srv := streamtest.NewServer("What's the weather in Cluj?")
+//	defer srv.Close()
+//	streamer := stt.AssemblyAIStreaming{URL: srv.URL(), APIKey: "test"}
+package streamtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/gorilla/websocket"
+)
+
+// Server reveals the transcript one word per audio message as partial
+// results of a single turn. When the client terminates, it sends the
+// whole turn, then its formatted version, and ends the session.
+type Server struct {
+	*httptest.Server
+
+	// CloseCode, when set, makes the server close every session with
+	// this code after the first audio message, as the real API does on
+	// errors such as an exhausted balance.
+	CloseCode   int
+	CloseReason string
+
+	words     []string
+	formatted string
+
+	mu         sync.Mutex
+	audioBytes int
+	messages   int
+	sampleRate int
+}
+
+func NewServer(transcript string) *Server {
+	s := &Server{words: strings.Fields(transcript), formatted: transcript}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// URL is the value to use as stt.AssemblyAIStreaming.URL.
+func (s *Server) URL() string {
+	return "ws" + strings.TrimPrefix(s.Server.URL, "http") + "/v3/ws"
+}
+
+// AudioBytes is the amount of PCM received over all sessions.
+func (s *Server) AudioBytes() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.audioBytes
+}
+
+// Messages is the number of audio messages received.
+func (s *Server) Messages() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.messages
+}
+
+// SampleRate is the rate the last session was opened with.
+func (s *Server) SampleRate() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sampleRate
+}
+
+var upgrader = websocket.Upgrader{}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v3/ws" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Header.Get("Authorization") == "" {
+		http.Error(w, `{"error": "Authentication error, API token missing/invalid"}`, http.StatusUnauthorized)
+		return
+	}
+
+	sampleRate, err := strconv.Atoi(r.URL.Query().Get("sample_rate"))
+	if err != nil || sampleRate <= 0 || r.URL.Query().Get("encoding") != "pcm_s16le" {
+		http.Error(w, `{"error": "invalid sample_rate or encoding"}`, http.StatusBadRequest)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	s.mu.Lock()
+	s.sampleRate = sampleRate
+	s.mu.Unlock()
+
+	conn.WriteJSON(map[string]interface{}{"type": "Begin", "id": "test-session", "expires_at": time.Now().Add(time.Hour).Unix()})
+
+	heard := 0
+	for {
+		kind, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		if kind == websocket.TextMessage {
+			var msg struct {
+				Type string `json:"type"`
+			}
+			json.Unmarshal(data, &msg)
+			if msg.Type == "Terminate" {
+				s.finish(conn)
+				return
+			}
+			continue
+		}
+
+		// Each message must hold between 50 ms and 1 s of audio.
+		duration := time.Duration(len(data)/2) * time.Second / time.Duration(sampleRate)
+		if duration < 50*time.Millisecond || duration > time.Second {
+			s.close(conn, 3007, "Input duration violation")
+			return
+		}
+
+		s.mu.Lock()
+		s.audioBytes += len(data)
+		s.messages++
+		s.mu.Unlock()
+
+		if s.CloseCode != 0 {
+			s.close(conn, s.CloseCode, s.CloseReason)
+			return
+		}
+
+		if heard < len(s.words) {
+			heard++
+			conn.WriteJSON(turn(unformatted(strings.Join(s.words[:heard], " ")), false, false))
+		}
+	}
+}
+
+func (s *Server) finish(conn *websocket.Conn) {
+	if len(s.words) > 0 {
+		conn.WriteJSON(turn(unformatted(s.formatted), true, false))
+		conn.WriteJSON(turn(s.formatted, true, true))
+	}
+	conn.WriteJSON(map[string]interface{}{"type": "Termination"})
+	s.close(conn, websocket.CloseNormalClosure, "")
+}
+
+func (s *Server) close(conn *websocket.Conn, code int, reason string) {
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(time.Second))
+}
+
+func turn(transcript string, endOfTurn, formatted bool) map[string]interface{} {
+	return map[string]interface{}{
+		"type":              "Turn",
+		"turn_order":        0,
+		"transcript":        transcript,
+		"end_of_turn":       endOfTurn,
+		"turn_is_formatted": formatted,
+	}
+}
+
+// unformatted is the transcript as it is streamed before formatting:
+// lower case and without punctuation.
+func unformatted(text string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r) && r != '\''
+	}), " ")
+}
@@ -0,0 +1,33 @@
+package stt
+
+import (
+	"KevinGo/audio"
+	"KevinGo/poll"
+	"context"
+	"fmt"
+)
+
+// ErrNoSpeech is returned when the recording contained no recognizable
+// speech. It is the error poll.StartPolling returns for an empty
+// transcript.
+var ErrNoSpeech = poll.ErrNoSpeech
+
+// Transcriber turns a recording into text.
+type Transcriber interface {
+	Name() string
+	Transcribe(ctx context.Context, rec audio.Recording) (string, error)
+}
+
+// New returns the configured transcriber for the given backend name. An
+// empty name selects AssemblyAI, which was the only backend before
+// whisper.cpp support.
+func New(backend string, assemblyAI AssemblyAI, whisper Whisper) (Transcriber, error) {
+	switch backend {
+	case "", "assemblyai":
+		return assemblyAI, nil
+	case "whisper":
+		return whisper, nil
+	default:
+		return nil, fmt.Errorf("unknown speech-to-text backend %q (use assemblyai or whisper)", backend)
+	}
+}
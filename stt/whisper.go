@@ -0,0 +1,115 @@
+package stt
+
+import (
+	"KevinGo/audio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+var nonSpeechTags = regexp.MustCompile(`\[[^\]]*\]|\([^)]*\)`)
+
+// Whisper runs a local whisper.cpp binary on the recording, so no audio
+// leaves the machine.
+type Whisper struct {
+	// Binary is the whisper.cpp executable. When empty, whisper-cli and
+	// whisper-cpp are looked up on PATH.
+	Binary string
+	// Model is the path to a ggml model such as ggml-base.en.bin.
+	Model string
+	// Language is passed to -l; "auto" lets whisper detect it.
+	Language string
+	Threads  int
+	// TempDir holds the recording while whisper.cpp reads it.
+	TempDir string
+}
+
+func (Whisper) Name() string {
+	return "whisper.cpp"
+}
+
+// Check reports whether the binary and the model are available, so a
+// broken setup is caught at startup rather than on the first request.
+func (w Whisper) Check() error {
+	if _, err := w.binary(); err != nil {
+		return err
+	}
+
+	if w.Model == "" {
+		return fmt.Errorf("no whisper model configured")
+	}
+	if _, err := os.Stat(w.Model); err != nil {
+		return fmt.Errorf("whisper model not found: %w", err)
+	}
+
+	return nil
+}
+
+func (w Whisper) Transcribe(ctx context.Context, rec audio.Recording) (string, error) {
+	if err := w.Check(); err != nil {
+		return "", err
+	}
+	binary, _ := w.binary()
+
+	// whisper.cpp reads a file, and only 16 kHz mono WAV. Each call gets
+	// its own file so concurrent transcriptions do not clash.
+	f, err := os.CreateTemp(w.TempDir, "whisper-*.wav")
+	if err != nil {
+		return "", fmt.Errorf("error creating whisper input: %w", err)
+	}
+	input := f.Name()
+	defer os.Remove(input)
+
+	err = rec.ForSpeech().Write(f, audio.WAV)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("error writing whisper input: %w", err)
+	}
+
+	language := w.Language
+	if language == "" {
+		language = "auto"
+	}
+
+	args := []string{"-m", w.Model, "-f", input, "-l", language, "-nt", "-np"}
+	if w.Threads > 0 {
+		args = append(args, "-t", fmt.Sprint(w.Threads))
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, binary, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("whisper.cpp error: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	// whisper.cpp marks silence and noise with tags like [BLANK_AUDIO].
+	text := nonSpeechTags.ReplaceAllString(stdout.String(), " ")
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return "", ErrNoSpeech
+	}
+
+	return text, nil
+}
+
+func (w Whisper) binary() (string, error) {
+	if w.Binary != "" {
+		return exec.LookPath(w.Binary)
+	}
+
+	for _, name := range []string{"whisper-cli", "whisper-cpp"} {
+		if path, err := exec.LookPath(name); err == nil {
+			return path, nil
+		}
+	}
+
+	return "", fmt.Errorf("whisper.cpp binary not found (install whisper-cli or set its path)")
+}
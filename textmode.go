@@ -0,0 +1,149 @@
+package main
+
+import (
+	"KevinGo/conversation"
+	"KevinGo/enhancedcontext"
+	"KevinGo/tts"
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/gordonklaus/portaudio"
+)
+
+// runTextMode chats over stdin and stdout without recording, for
+// terminals without a microphone. PortAudio is only used to speak the
+// answers on audio.output_device. When stdin is piped, every line is a
+// question, only the answers are written to stdout and status messages go
+// to stderr, so Kira can be scripted.
+func runTextMode() {
+	interactive := isTerminal(os.Stdin)
+
+	if !interactive {
+		status = os.Stderr
+		log.SetOutput(os.Stderr)
+	}
+
+	printWarnings(status)
+	if !checkOllama(status) {
+		os.Exit(1)
+	}
+
+	var synthesizers tts.Chain
+	if cfg.Text.Speak {
+		var err error
+		synthesizers, err = newSynthesizers()
+		if err != nil {
+			fmt.Fprintf(status, "❌ %v\n", err)
+			os.Exit(2)
+		}
+		if cfg.Audio.OutputDevice != "" {
+			portaudio.Initialize()
+			defer portaudio.Terminate()
+			if err := selectDevices(status, false); err != nil {
+				fmt.Fprintf(status, "❌ %v\n", err)
+				os.Exit(2)
+			}
+		}
+		fmt.Fprintln(status, "🔊 Answers will be spoken")
+	}
+
+	if interactive {
+		fmt.Println("⌨️ Text mode activated! Type a question, or \"exit\" to quit.")
+	}
+
+	session := newSession(status)
+	enhancedcontext.OnTimer = func(announcement string) {
+		fmt.Fprintf(status, "\n⏰ %s\n", announcement)
+		if cfg.Text.Speak {
+			speak(synthesizers, announcement)
+		}
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	failed := false
+
+	for {
+		if interactive {
+			fmt.Print("\n🧑 You: ")
+		}
+		if !scanner.Scan() {
+			break
+		}
+
+		question := strings.TrimSpace(scanner.Text())
+		if question == "" {
+			continue
+		}
+		if interactive && (question == "exit" || question == "quit") {
+			break
+		}
+
+		response, err := answerText(session, question)
+		if errors.Is(err, context.Canceled) {
+			fmt.Fprintln(status, "\n⏹️ Question cancelled")
+			continue
+		} else if err != nil {
+			log.Printf("❌ Ollama error: %v", err)
+			failed = true
+			continue
+		}
+
+		if interactive {
+			fmt.Printf("💬 Kira: %s\n", response)
+		} else {
+			fmt.Println(strings.TrimSpace(response))
+		}
+
+		if cfg.Text.Speak {
+			speak(synthesizers, response)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		log.Printf("❌ Input error: %v", err)
+		failed = true
+	}
+
+	if interactive {
+		fmt.Println("\n👋 Goodbye!")
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func speak(synthesizers tts.Chain, response string) {
+	voice := newSpeaker(context.Background(), synthesizers)
+	voice.Write(response)
+	if err := voice.Close(); err != nil {
+		log.Printf("❌ Could not speak the response: %v", err)
+	}
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// answerText answers one question. Control+C while Kira is working on it
+// cancels the skill lookups and the reply; at the prompt it still quits.
+func answerText(session *conversation.Session, question string) (string, error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	turn := enhancedcontext.Prepare(ctx, question)
+	if turn.Answer != "" {
+		session.Remember(question, turn.Answer)
+		return turn.Answer, nil
+	}
+	return session.AskStream(ctx, question, turn.Context, nil)
+}
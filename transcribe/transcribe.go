@@ -1,40 +1,68 @@
 package transcribe
 
 import (
+	"KevinGo/apierror"
 	"KevinGo/upload"
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 )
 
-func Transcribe() string {
-	audioURL := upload.Upload()
+// TranscriptURL is the AssemblyAI transcript endpoint, where transcripts
+// are started and, by package poll, fetched. It is a variable so a local
+// stand-in such as polltest.Server can take its place.
+var TranscriptURL = "https://api.assemblyai.com/v2/transcript"
 
-	const API_KEY = "Your Key"
-	const TRANSCRIBE_URL = "https://api.assemblyai.com/v2/transcript"
+// ErrNoID is returned when the transcript was accepted but no ID came back.
+var ErrNoID = errors.New("AssemblyAI returned no transcript ID")
+
+// Transcribe uploads the audio and starts a transcript of it with apiKey,
+// returning the transcript ID. HTTP failures of either step are
+// *apierror.StatusError values.
+func Transcribe(ctx context.Context, apiKey string, audio io.Reader, contentType string) (string, error) {
+	audioURL, err := upload.Upload(ctx, apiKey, audio, contentType)
+	if err != nil {
+		return "", fmt.Errorf("upload failed: %w", err)
+	}
 
 	values := map[string]string{"audio_url": audioURL}
-	jsonData, _ := json.Marshal(values)
+	jsonData, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
 
-	client := &http.Client{}
-	req, _ := http.NewRequest("POST", TRANSCRIBE_URL, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", TranscriptURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("error creating request: %w", err)
+	}
 	req.Header.Set("content-type", "application/json")
-	req.Header.Set("authorization", API_KEY)
-
-	res, err := client.Do(req)
+	req.Header.Set("authorization", apiKey)
 
+	res, err := upload.HTTPClient.Do(req)
 	if err != nil {
-		return ""
+		return "", apierror.Transport("AssemblyAI", err)
 	}
 
 	defer res.Body.Close()
 
-	var result map[string]interface{}
-	json.NewDecoder(res.Body).Decode(&result)
+	if err := apierror.Check("AssemblyAI", res); err != nil {
+		return "", err
+	}
+
+	var result struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("error parsing JSON: %w", err)
+	}
 
-	if id, ok := result["id"].(string); ok {
-		return id
+	if result.ID == "" {
+		return "", ErrNoID
 	}
 
-	return ""
+	return result.ID, nil
 }
@@ -0,0 +1,52 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+)
+
+// Espeak runs espeak-ng, which is available on almost every Linux
+// distribution and needs no model files.
+type Espeak struct {
+	Binary string
+	Voice  string
+	// Rate is in words per minute.
+	Rate int
+}
+
+func (Espeak) Name() string {
+	return "espeak-ng"
+}
+
+func (e Espeak) Synthesize(ctx context.Context, text string) (*Audio, error) {
+	binary := e.Binary
+	if binary == "" {
+		binary = "espeak-ng"
+	}
+	if _, err := exec.LookPath(binary); err != nil {
+		return nil, fmt.Errorf("espeak-ng not available")
+	}
+
+	args := []string{"--stdout"}
+	if e.Voice != "" {
+		args = append(args, "-v", e.Voice)
+	}
+	if e.Rate > 0 {
+		args = append(args, "-s", fmt.Sprint(e.Rate))
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, binary, append(args, text)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("espeak-ng error: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	return &Audio{Data: stdout.Bytes(), Format: "wav"}, nil
+}
@@ -0,0 +1,67 @@
+package tts
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	htgotts "github.com/hegedustibor/htgo-tts"
+	"github.com/hegedustibor/htgo-tts/voices"
+)
+
+// HTGO uses htgo-tts, which calls Google Translate and needs internet.
+type HTGO struct {
+	Language string
+	TempDir  string
+}
+
+func (HTGO) Name() string {
+	return "htgo-tts"
+}
+
+// Synthesize retries with the first 15 words when the full text fails,
+// since the service rejects long requests.
+func (h HTGO) Synthesize(ctx context.Context, text string) (*Audio, error) {
+	audio, err := h.synthesize(text)
+	if err == nil || len(text) <= 100 {
+		return audio, err
+	}
+
+	words := strings.Fields(text)
+	if len(words) <= 15 {
+		return nil, err
+	}
+
+	return h.synthesize(strings.Join(words[:15], " ") + ".")
+}
+
+func (h HTGO) synthesize(text string) (*Audio, error) {
+	language := h.Language
+	if language == "" {
+		language = voices.English
+	}
+
+	dir, err := os.MkdirTemp(h.TempDir, "htgo-*")
+	if err != nil {
+		return nil, fmt.Errorf("error creating temp folder: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	speech := htgotts.Speech{
+		Folder:   dir,
+		Language: language,
+	}
+
+	path, err := speech.CreateSpeechFile(text, "response")
+	if err != nil {
+		return nil, fmt.Errorf("htgo-tts error: %w", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("htgo-tts error: %w", err)
+	}
+
+	return &Audio{Data: data, Format: "mp3"}, nil
+}
@@ -0,0 +1,59 @@
+package tts
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// piperRate is the speaking rate, in words per minute, that Piper voices
+// have at length_scale 1.
+const piperRate = 180
+
+// Piper runs the local Piper neural TTS engine.
+type Piper struct {
+	Binary string
+	// Model is the path to a voice such as en_US-amy-medium.onnx.
+	Model string
+	// Rate is in words per minute.
+	Rate    int
+	TempDir string
+}
+
+func (Piper) Name() string {
+	return "Piper"
+}
+
+func (p Piper) Synthesize(ctx context.Context, text string) (*Audio, error) {
+	binary := p.Binary
+	if binary == "" {
+		binary = "piper"
+	}
+	if _, err := exec.LookPath(binary); err != nil {
+		return nil, fmt.Errorf("piper not available")
+	}
+
+	if p.Model == "" {
+		return nil, fmt.Errorf("no piper voice model configured")
+	}
+	if _, err := os.Stat(p.Model); err != nil {
+		return nil, fmt.Errorf("piper voice model not found: %w", err)
+	}
+
+	data, err := runToFile(ctx, p.TempDir, "wav", func(path string) *exec.Cmd {
+		args := []string{"--model", p.Model, "--output_file", path}
+		if p.Rate > 0 {
+			args = append(args, "--length_scale", fmt.Sprintf("%.2f", float64(piperRate)/float64(p.Rate)))
+		}
+		cmd := exec.CommandContext(ctx, binary, args...)
+		cmd.Stdin = strings.NewReader(text)
+		return cmd
+	})
+	if err != nil {
+		return nil, fmt.Errorf("piper error: %w", err)
+	}
+
+	return &Audio{Data: data, Format: "wav"}, nil
+}
@@ -0,0 +1,46 @@
+package tts
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+)
+
+// Say uses the macOS say command.
+type Say struct {
+	Voice string
+	// Rate is in words per minute.
+	Rate    int
+	TempDir string
+}
+
+func (Say) Name() string {
+	return "macOS say command"
+}
+
+func (s Say) Synthesize(ctx context.Context, text string) (*Audio, error) {
+	if runtime.GOOS != "darwin" {
+		return nil, fmt.Errorf("say command only available on macOS")
+	}
+
+	if _, err := exec.LookPath("say"); err != nil {
+		return nil, fmt.Errorf("say command not available")
+	}
+
+	data, err := runToFile(ctx, s.TempDir, "aiff", func(path string) *exec.Cmd {
+		args := []string{"-o", path}
+		if s.Voice != "" {
+			args = append(args, "-v", s.Voice)
+		}
+		if s.Rate > 0 {
+			args = append(args, "-r", fmt.Sprint(s.Rate))
+		}
+		return exec.CommandContext(ctx, "say", append(args, text)...)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("say command error: %w", err)
+	}
+
+	return &Audio{Data: data, Format: "aiff"}, nil
+}
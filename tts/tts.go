@@ -0,0 +1,91 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// Audio is a synthesized clip. Format is the file extension that matches
+// Data: "mp3", "wav" or "aiff".
+type Audio struct {
+	Data   []byte
+	Format string
+}
+
+// Synthesizer turns text into speech.
+type Synthesizer interface {
+	Name() string
+	Synthesize(ctx context.Context, text string) (*Audio, error)
+}
+
+// Chain tries each synthesizer in order until one produces valid audio.
+type Chain []Synthesizer
+
+// AttemptFunc is told about each backend that is tried and why it
+// failed, so the caller can report progress.
+type AttemptFunc func(name string, err error)
+
+func (c Chain) Synthesize(ctx context.Context, text string, onAttempt AttemptFunc) (*Audio, string, error) {
+	for _, synthesizer := range c {
+		audio, err := synthesizer.Synthesize(ctx, text)
+		if err == nil && !IsValidAudio(audio.Data) {
+			err = errors.New("generated invalid audio")
+		}
+		if onAttempt != nil {
+			onAttempt(synthesizer.Name(), err)
+		}
+		if err == nil {
+			return audio, synthesizer.Name(), nil
+		}
+		if ctx.Err() != nil {
+			return nil, "", ctx.Err()
+		}
+	}
+
+	return nil, "", fmt.Errorf("all TTS methods failed")
+}
+
+// IsValidAudio rejects empty clips and HTML error pages that online
+// services sometimes return instead of audio.
+func IsValidAudio(data []byte) bool {
+	if len(data) < 1000 {
+		return false
+	}
+
+	header := data[:min(len(data), 100)]
+	if bytes.Contains(header, []byte("<!DOCTYPE")) ||
+		bytes.Contains(header, []byte("<html")) ||
+		bytes.Contains(header, []byte("<HTML")) {
+		return false
+	}
+
+	return true
+}
+
+// runToFile runs a command that writes its output to a temporary file with
+// the given extension and returns the file contents.
+func runToFile(ctx context.Context, tempDir, ext string, build func(path string) *exec.Cmd) ([]byte, error) {
+	f, err := os.CreateTemp(tempDir, "tts-*."+ext)
+	if err != nil {
+		return nil, fmt.Errorf("error creating temp file: %w", err)
+	}
+	path := f.Name()
+	f.Close()
+	defer os.Remove(path)
+
+	var stderr bytes.Buffer
+	cmd := build(path)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("%w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	return os.ReadFile(path)
+}
@@ -1,53 +1,70 @@
 package upload
 
 import (
-	"bytes"
+	"KevinGo/apierror"
+	"context"
 	"encoding/json"
-	"io/ioutil"
-	"log"
+	"errors"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
-	"path/filepath"
+	"time"
 )
 
-func Upload() string {
-	const API_KEY = "Your Key"
-	const UPLOAD_URL = "https://api.assemblyai.com/v2/upload"
-	audioPath := filepath.Join("assets", "audio.m4a")
+var UploadURL = "https://api.assemblyai.com/v2/upload"
 
-	data, err := ioutil.ReadFile(audioPath)
-	if err != nil {
-		log.Println(err)
-		return ""
-	}
+// HTTPClient gives up on connecting after 10 seconds and on an answer
+// that has not started 30 seconds after the audio was sent. The transfer
+// itself is bounded by the caller's context.
+var HTTPClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           (&net.Dialer{Timeout: 10 * time.Second}).DialContext,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 30 * time.Second,
+		IdleConnTimeout:       90 * time.Second,
+	},
+}
+
+// ErrNoURL is returned when the upload was accepted but no URL came back.
+var ErrNoURL = errors.New("AssemblyAI upload returned no URL")
 
-	client := &http.Client{}
-	req, err := http.NewRequest("POST", UPLOAD_URL, bytes.NewBuffer(data))
+// Upload sends the audio with apiKey and returns the URL AssemblyAI
+// stored it at. The audio is read as it is sent, with chunked transfer
+// encoding when its length is not known in advance. HTTP failures are
+// *apierror.StatusError values; a stalled transfer matches
+// apierror.ErrTimeout once ctx expires.
+func Upload(ctx context.Context, apiKey string, audio io.Reader, contentType string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", UploadURL, audio)
 	if err != nil {
-		log.Printf("Error creating request: %v", err)
-		return ""
+		return "", fmt.Errorf("error creating request: %w", err)
 	}
 
-	req.Header.Set("authorization", API_KEY)
-	req.Header.Set("content-type", "audio/m4a")
+	req.Header.Set("authorization", apiKey)
+	req.Header.Set("content-type", contentType)
 
-	res, err := client.Do(req)
+	res, err := HTTPClient.Do(req)
 	if err != nil {
-		log.Println(err)
-		return ""
+		return "", apierror.Transport("AssemblyAI upload", err)
 	}
 
 	defer res.Body.Close()
 
-	var result map[string]interface{}
-	err = json.NewDecoder(res.Body).Decode(&result)
-	if err != nil {
-		log.Println(err)
-		return ""
+	if err := apierror.Check("AssemblyAI upload", res); err != nil {
+		return "", err
+	}
+
+	var result struct {
+		UploadURL string `json:"upload_url"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("error parsing JSON: %w", err)
 	}
 
-	if uploadURL, ok := result["upload_url"].(string); ok {
-		return uploadURL
+	if result.UploadURL == "" {
+		return "", ErrNoURL
 	}
 
-	return ""
+	return result.UploadURL, nil
 }
@@ -0,0 +1,215 @@
+package vad
+
+import (
+	"math"
+	"time"
+)
+
+type Event int
+
+const (
+	None Event = iota
+	// SpeechStarted is reported on the first window classified as speech.
+	SpeechStarted
+	// SpeechEnded is reported once speech was followed by enough silence.
+	SpeechEnded
+	// Discarded is reported when speech stopped before reaching MinSpeech,
+	// such as a cough or a door closing. The detector starts over.
+	Discarded
+	// MaxLengthReached is reported when the utterance hit MaxUtterance.
+	MaxLengthReached
+)
+
+type Config struct {
+	SampleRate int
+	// Threshold is the minimum RMS level (on the int16 scale) for a window
+	// to count as speech. The detector raises it above the measured
+	// background noise when the room is louder.
+	Threshold float64
+	// Silence is how long the user must stay quiet to end the utterance.
+	Silence time.Duration
+	// MaxUtterance caps the length of a single request.
+	MaxUtterance time.Duration
+	// MinSpeech is the shortest stretch of speech that is kept.
+	MinSpeech time.Duration
+}
+
+func DefaultConfig(sampleRate int) Config {
+	return Config{
+		SampleRate:   sampleRate,
+		Threshold:    500,
+		Silence:      time.Second,
+		MaxUtterance: 15 * time.Second,
+		MinSpeech:    300 * time.Millisecond,
+	}
+}
+
+// windowDuration is the analysis window; PortAudio buffers are much shorter
+// so samples are accumulated until a full window is available.
+const windowDuration = 20 * time.Millisecond
+
+// Detector is an energy and zero-crossing voice activity detector. Feed it
+// every captured buffer with Process and react to the returned events.
+type Detector struct {
+	cfg Config
+
+	windowSize int
+	window     []int16
+
+	noiseFloor    float64
+	inSpeech      bool
+	speechWindows int
+	silentWindows int
+	totalWindows  int
+}
+
+func New(cfg Config) *Detector {
+	windowSize := int(float64(cfg.SampleRate) * windowDuration.Seconds())
+	if windowSize <= 0 {
+		windowSize = 1
+	}
+
+	return &Detector{
+		cfg:        cfg,
+		windowSize: windowSize,
+		window:     make([]int16, 0, windowSize),
+	}
+}
+
+// Process consumes a buffer of samples and returns the most significant
+// event that happened while doing so.
+func (d *Detector) Process(samples []int16) Event {
+	event := None
+
+	for _, sample := range samples {
+		d.window = append(d.window, sample)
+		if len(d.window) < d.windowSize {
+			continue
+		}
+
+		if e := d.processWindow(d.window); e != None {
+			event = e
+		}
+		d.window = d.window[:0]
+
+		if event == SpeechEnded || event == MaxLengthReached {
+			return event
+		}
+	}
+
+	return event
+}
+
+// InSpeech reports whether the detector is inside an utterance.
+func (d *Detector) InSpeech() bool {
+	return d.inSpeech
+}
+
+// Reset clears the utterance state but keeps the measured noise floor.
+func (d *Detector) Reset() {
+	d.window = d.window[:0]
+	d.inSpeech = false
+	d.speechWindows = 0
+	d.silentWindows = 0
+	d.totalWindows = 0
+}
+
+func (d *Detector) processWindow(window []int16) Event {
+	speech := d.isSpeech(window)
+
+	if !d.inSpeech {
+		if !speech {
+			return None
+		}
+		d.inSpeech = true
+		d.speechWindows = 1
+		d.silentWindows = 0
+		d.totalWindows = 1
+		return SpeechStarted
+	}
+
+	d.totalWindows++
+	if speech {
+		d.speechWindows++
+		d.silentWindows = 0
+	} else {
+		d.silentWindows++
+	}
+
+	if d.windowsFor(d.cfg.MaxUtterance) > 0 && d.totalWindows >= d.windowsFor(d.cfg.MaxUtterance) {
+		d.Reset()
+		return MaxLengthReached
+	}
+
+	if d.silentWindows >= d.windowsFor(d.cfg.Silence) {
+		enough := d.speechWindows >= d.windowsFor(d.cfg.MinSpeech)
+		d.Reset()
+		if !enough {
+			return Discarded
+		}
+		return SpeechEnded
+	}
+
+	return None
+}
+
+func (d *Detector) isSpeech(window []int16) bool {
+	level := RMS(window)
+	zcr := zeroCrossingRate(window)
+
+	threshold := math.Max(d.cfg.Threshold, d.noiseFloor*3)
+	speech := level > threshold && zcr < 0.5
+
+	// A window just below the threshold still counts while speaking if its
+	// zero-crossing rate looks like voiced or fricative sounds, so word
+	// endings are not cut off.
+	if !speech && d.inSpeech && level > threshold/2 && zcr > 0.02 && zcr < 0.35 {
+		speech = true
+	}
+
+	if !speech {
+		// Track the background noise slowly so a fan or traffic does not
+		// read as speech.
+		if d.noiseFloor == 0 {
+			d.noiseFloor = level
+		} else {
+			d.noiseFloor = 0.95*d.noiseFloor + 0.05*level
+		}
+	}
+
+	return speech
+}
+
+func (d *Detector) windowsFor(duration time.Duration) int {
+	return int(duration / windowDuration)
+}
+
+// RMS returns the root mean square level of the samples on the int16 scale.
+func RMS(samples []int16) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+
+	var sum float64
+	for _, sample := range samples {
+		v := float64(sample)
+		sum += v * v
+	}
+
+	return math.Sqrt(sum / float64(len(samples)))
+}
+
+func zeroCrossingRate(samples []int16) float64 {
+	if len(samples) < 2 {
+		return 0
+	}
+
+	crossings := 0
+	for i := 1; i < len(samples); i++ {
+		if (samples[i-1] >= 0) != (samples[i] >= 0) {
+			crossings++
+		}
+	}
+
+	return float64(crossings) / float64(len(samples)-1)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"KevinGo/stt"
+	"KevinGo/vad"
+	"KevinGo/wakeword"
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+func newWakeDetector() (*wakeword.Detector, error) {
+	whisper := stt.Whisper{
+		Binary:   cfg.STT.Whisper.Binary,
+		Model:    cfg.Wake.WhisperModel,
+		Language: cfg.STT.Whisper.Language,
+		TempDir:  cfg.Audio.AssetsDir,
+	}
+	if whisper.Model == "" {
+		whisper.Model = cfg.STT.Whisper.Model
+	}
+
+	if err := whisper.Check(); err != nil {
+		return nil, fmt.Errorf("wake word detection needs a local whisper.cpp: %w", err)
+	}
+
+	return &wakeword.Detector{
+		Phrase:      cfg.Wake.Phrase,
+		Sensitivity: cfg.Wake.Sensitivity,
+		Whisper:     whisper,
+	}, nil
+}
+
+// recordAfterWakeWord listens until the wake phrase is heard and then
+// records the request that follows it. Short clips of speech are checked
+// locally; the ring buffer keeps the audio around each clip so a request
+// spoken in the same breath as the wake phrase is not lost.
+func recordAfterWakeWord(detector *wakeword.Detector) ([]int16, error) {
+	mic, err := openMicrophone(512)
+	if err != nil {
+		return nil, err
+	}
+	defer mic.Close()
+
+	sampleRate := cfg.Audio.SampleRate
+	ring := wakeword.NewRingBuffer(int(cfg.Wake.PreRoll.Seconds() * float64(sampleRate)))
+	preRoll := int64(sampleRate * 3 / 10)
+
+	clipConfig := vadConfig()
+	clipConfig.Threshold *= detector.ThresholdScale()
+	clipConfig.Silence = 400 * time.Millisecond
+	clipConfig.MaxUtterance = 3 * time.Second
+	clipConfig.MinSpeech = 200 * time.Millisecond
+	clips := vad.New(clipConfig)
+
+	var clipStart int64
+
+	fmt.Printf("💤 Waiting for \"%s\"...\n", cfg.Wake.Phrase)
+
+	for frame := range mic.frames {
+		ring.Write(frame)
+
+		switch clips.Process(frame) {
+		case vad.SpeechStarted:
+			clipStart = ring.Position() - int64(len(frame)) - preRoll
+		case vad.SpeechEnded, vad.MaxLengthReached:
+			clip := ring.Since(clipStart)
+			match, err := detector.Detect(context.Background(), clip, sampleRate)
+			if err != nil {
+				log.Printf("⚠️ Wake word check failed: %v", err)
+				continue
+			}
+			if !match.Detected {
+				continue
+			}
+
+			fmt.Println("👂 Wake word detected!")
+
+			var seed []int16
+			if match.Remainder != "" {
+				seed = clip
+			}
+
+			return captureUtterance(mic.frames, seed, cfg.Wake.Timeout)
+		}
+	}
+
+	return nil, mic.Err()
+}
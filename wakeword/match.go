@@ -0,0 +1,118 @@
+package wakeword
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Match is the result of looking for the wake phrase in a transcript.
+type Match struct {
+	Detected bool
+	// Score is the similarity of the best candidate, from 0 to 1.
+	Score float64
+	// Remainder is whatever the user said after the wake phrase in the
+	// same breath, such as "what's the weather" in "Hey Kira, what's the
+	// weather".
+	Remainder string
+}
+
+// matchPhrase looks for phrase in transcript, tolerating the spelling
+// variations speech recognition produces for names ("Keira", "Kiera").
+// minScore is the similarity from 0 to 1 a candidate needs to count.
+func matchPhrase(transcript, phrase string, minScore float64, allowNameOnly bool) Match {
+	words := normalizeWords(transcript)
+	target := normalizeWords(phrase)
+	if len(words) == 0 || len(target) == 0 {
+		return Match{}
+	}
+
+	candidates := [][]string{target}
+	if allowNameOnly && len(target) > 1 {
+		candidates = append(candidates, target[len(target)-1:])
+	}
+
+	best := Match{}
+	originals := strings.Fields(transcript)
+
+	for _, candidate := range candidates {
+		for start := 0; start+len(candidate) <= len(words); start++ {
+			score := 0.0
+			for i, word := range candidate {
+				score += similarity(words[start+i], word)
+			}
+			score /= float64(len(candidate))
+
+			if score > best.Score {
+				best.Score = score
+				best.Detected = score >= minScore
+				best.Remainder = ""
+				if end := start + len(candidate); best.Detected && end < len(originals) && len(originals) == len(words) {
+					best.Remainder = strings.TrimLeft(strings.Join(originals[end:], " "), ",.!?;: ")
+				}
+			}
+		}
+	}
+
+	return best
+}
+
+// normalizeWords lowercases the text, strips diacritics and punctuation and
+// splits it into words.
+func normalizeWords(text string) []string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, text)
+	if err != nil {
+		folded = text
+	}
+
+	var words []string
+	for _, field := range strings.Fields(strings.ToLower(folded)) {
+		word := strings.TrimFunc(field, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		words = append(words, word)
+	}
+
+	return words
+}
+
+// similarity is 1 minus the Levenshtein distance relative to the longer
+// word, so identical words score 1 and unrelated words score near 0.
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 0
+	}
+
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
@@ -0,0 +1,60 @@
+package wakeword
+
+// RingBuffer keeps the most recent samples of the microphone stream and
+// remembers how many samples were written in total, so callers can ask for
+// everything captured since a given position.
+type RingBuffer struct {
+	data    []int16
+	written int64
+}
+
+func NewRingBuffer(capacity int) *RingBuffer {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &RingBuffer{data: make([]int16, capacity)}
+}
+
+func (r *RingBuffer) Write(samples []int16) {
+	for _, sample := range samples {
+		r.data[r.written%int64(len(r.data))] = sample
+		r.written++
+	}
+}
+
+// Position is the total number of samples written so far.
+func (r *RingBuffer) Position() int64 {
+	return r.written
+}
+
+// Since returns the samples written after position pos, oldest first. If
+// part of that range was already overwritten, only the retained tail is
+// returned.
+func (r *RingBuffer) Since(pos int64) []int16 {
+	oldest := r.written - int64(len(r.data))
+	if oldest < 0 {
+		oldest = 0
+	}
+	if pos < oldest {
+		pos = oldest
+	}
+	if pos >= r.written {
+		return nil
+	}
+
+	out := make([]int16, 0, r.written-pos)
+	for i := pos; i < r.written; i++ {
+		out = append(out, r.data[i%int64(len(r.data))])
+	}
+
+	return out
+}
+
+// Last returns up to n of the most recent samples.
+func (r *RingBuffer) Last(n int) []int16 {
+	return r.Since(r.written - int64(n))
+}
+
+func (r *RingBuffer) Reset() {
+	r.written = 0
+}
@@ -0,0 +1,68 @@
+package wakeword
+
+import (
+	"KevinGo/audio"
+	"KevinGo/stt"
+	"context"
+	"errors"
+)
+
+// Detector decides whether a short stretch of audio contains the wake
+// phrase. It only accepts the whisper.cpp backend so nothing leaves the
+// machine while Kira is idle.
+type Detector struct {
+	Phrase string
+	// Sensitivity goes from 0 (only near-exact matches) to 1 (accept loose
+	// matches and the name on its own).
+	Sensitivity float64
+	Whisper     stt.Whisper
+}
+
+// Detect transcribes the clip locally and looks for the wake phrase in it.
+func (d *Detector) Detect(ctx context.Context, samples []int16, sampleRate int) (Match, error) {
+	clip := audio.Recording{Samples: samples, SampleRate: sampleRate, Channels: 1}
+	transcript, err := d.Whisper.Transcribe(ctx, clip)
+	if errors.Is(err, stt.ErrNoSpeech) {
+		return Match{}, nil
+	}
+	if err != nil {
+		return Match{}, err
+	}
+
+	return d.Match(transcript), nil
+}
+
+// Match looks for the wake phrase in an existing transcript.
+func (d *Detector) Match(transcript string) Match {
+	return matchPhrase(transcript, d.Phrase, d.minScore(), d.Sensitivity >= 0.5)
+}
+
+// Strip removes a leading wake phrase from the transcript of a request that
+// was recorded together with it.
+func (d *Detector) Strip(transcript string) string {
+	match := d.Match(transcript)
+	if match.Detected && match.Remainder != "" {
+		return match.Remainder
+	}
+	return transcript
+}
+
+// ThresholdScale adjusts the speech energy threshold used to find candidate
+// clips: higher sensitivity reacts to quieter speech.
+func (d *Detector) ThresholdScale() float64 {
+	return 1.5 - clamp(d.Sensitivity)
+}
+
+func (d *Detector) minScore() float64 {
+	return 1 - 0.5*clamp(d.Sensitivity)
+}
+
+func clamp(sensitivity float64) float64 {
+	if sensitivity < 0 {
+		return 0
+	}
+	if sensitivity > 1 {
+		return 1
+	}
+	return sensitivity
+}
@@ -0,0 +1,250 @@
+package weatherapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	openMeteoURL    = "https://api.open-meteo.com"
+	openMeteoGeoURL = "https://geocoding-api.open-meteo.com"
+)
+
+// OpenMeteo needs no API key. City names are turned into coordinates with
+// Open-Meteo's geocoding API first.
+type OpenMeteo struct {
+	// BaseURL and GeocodingURL default to the public APIs.
+	BaseURL      string
+	GeocodingURL string
+	Client       *http.Client
+}
+
+// Place is a geocoding result.
+type Place struct {
+	Name        string `json:"name"`
+	Country     string `json:"country"`
+	CountryCode string `json:"country_code"`
+	// Admin1 is the state, region or county.
+	Admin1     string  `json:"admin1"`
+	Latitude   float64 `json:"latitude"`
+	Longitude  float64 `json:"longitude"`
+	Population int     `json:"population"`
+	// FeatureCode is the GeoNames class, such as PPLC for a capital or
+	// ADM1 for a state.
+	FeatureCode string `json:"feature_code"`
+}
+
+// String names the place with its region and country, such as
+// "Paris, Texas, United States".
+func (p Place) String() string {
+	parts := []string{p.Name}
+	if p.Admin1 != "" && p.Admin1 != p.Name {
+		parts = append(parts, p.Admin1)
+	}
+	if p.Country != "" {
+		parts = append(parts, p.Country)
+	}
+	return strings.Join(parts, ", ")
+}
+
+type openMeteoGeocodingResponse struct {
+	Results []Place `json:"results"`
+}
+
+type openMeteoForecastResponse struct {
+	UTCOffsetSeconds int `json:"utc_offset_seconds"`
+	Current          struct {
+		Time          string  `json:"time"`
+		Temperature   float32 `json:"temperature_2m"`
+		Humidity      float32 `json:"relative_humidity_2m"`
+		Precipitation float32 `json:"precipitation"`
+		WeatherCode   int     `json:"weather_code"`
+		WindSpeed     float32 `json:"wind_speed_10m"`
+	} `json:"current"`
+	Hourly struct {
+		Time          []string  `json:"time"`
+		Temperature   []float32 `json:"temperature_2m"`
+		Humidity      []float32 `json:"relative_humidity_2m"`
+		RainChance    []float32 `json:"precipitation_probability"`
+		Precipitation []float32 `json:"precipitation"`
+		WeatherCode   []int     `json:"weather_code"`
+		WindSpeed     []float32 `json:"wind_speed_10m"`
+	} `json:"hourly"`
+}
+
+func (o *OpenMeteo) Name() string {
+	return "Open-Meteo"
+}
+
+// GetForecast returns the current conditions followed by the hourly
+// forecast of the next seven days.
+func (o *OpenMeteo) GetForecast(ctx context.Context, city string) (*Forecast, error) {
+	places, err := o.Geocode(ctx, city, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(places) == 0 {
+		return nil, fmt.Errorf("no weather data found for city %s", city)
+	}
+
+	return o.ForecastFor(ctx, places[0])
+}
+
+// ForecastFor returns the forecast for a place that was already geocoded.
+func (o *OpenMeteo) ForecastFor(ctx context.Context, place Place) (*Forecast, error) {
+	baseURL := o.BaseURL
+	if baseURL == "" {
+		baseURL = openMeteoURL
+	}
+
+	query := url.Values{
+		"latitude":        {strconv.FormatFloat(place.Latitude, 'f', 4, 64)},
+		"longitude":       {strconv.FormatFloat(place.Longitude, 'f', 4, 64)},
+		"current":         {"temperature_2m,relative_humidity_2m,precipitation,weather_code,wind_speed_10m"},
+		"hourly":          {"temperature_2m,relative_humidity_2m,precipitation_probability,precipitation,weather_code,wind_speed_10m"},
+		"forecast_days":   {"7"},
+		"wind_speed_unit": {"ms"},
+		"timezone":        {"auto"},
+	}
+
+	var response openMeteoForecastResponse
+	if err := getJSON(ctx, o.Client, baseURL+"/v1/forecast?"+query.Encode(), &response); err != nil {
+		return nil, err
+	}
+
+	location := time.FixedZone(place.Name, response.UTCOffsetSeconds)
+	current := response.Current
+	currentTime, err := time.ParseInLocation(openMeteoTimeLayout, current.Time, location)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing JSON: %w", err)
+	}
+
+	forecast := &Forecast{Location: place.Name}
+	forecast.Slots = append(forecast.Slots, WeatherData{
+		Time:          currentTime,
+		Day:           currentTime.Format(time.DateTime),
+		Location:      place.Name,
+		Temperature:   current.Temperature,
+		Description:   describeWeatherCode(current.WeatherCode),
+		Precipitation: current.Precipitation,
+		Wind:          current.WindSpeed,
+		Humidity:      current.Humidity,
+	})
+
+	hourly := response.Hourly
+	for i, value := range hourly.Time {
+		slotTime, err := time.ParseInLocation(openMeteoTimeLayout, value, location)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing JSON: %w", err)
+		}
+
+		// The current conditions stand in for the hour they fall in,
+		// except for the rain chance, which only the hourly data has.
+		if !slotTime.After(currentTime) {
+			if slotTime.Add(time.Hour).After(currentTime) {
+				forecast.Slots[0].RainChance = at(hourly.RainChance, i)
+			}
+			continue
+		}
+
+		forecast.Slots = append(forecast.Slots, WeatherData{
+			Time:          slotTime,
+			Day:           slotTime.Format(time.DateTime),
+			Location:      place.Name,
+			Temperature:   at(hourly.Temperature, i),
+			Description:   describeWeatherCode(int(at(hourly.WeatherCode, i))),
+			Precipitation: at(hourly.Precipitation, i),
+			Wind:          at(hourly.WindSpeed, i),
+			Humidity:      at(hourly.Humidity, i),
+			RainChance:    at(hourly.RainChance, i),
+		})
+	}
+
+	return forecast, nil
+}
+
+// openMeteoTimeLayout is the local time format used with timezone=auto.
+const openMeteoTimeLayout = "2006-01-02T15:04"
+
+// at guards against hourly arrays of different lengths, which Open-Meteo
+// should never send.
+func at[T int | float32](values []T, i int) float32 {
+	if i >= len(values) {
+		return 0
+	}
+	return float32(values[i])
+}
+
+// Geocode returns up to count places matching name, most relevant first.
+func (o *OpenMeteo) Geocode(ctx context.Context, name string, count int) ([]Place, error) {
+	geocodingURL := o.GeocodingURL
+	if geocodingURL == "" {
+		geocodingURL = openMeteoGeoURL
+	}
+
+	query := url.Values{
+		"name":     {name},
+		"count":    {strconv.Itoa(count)},
+		"language": {"en"},
+		"format":   {"json"},
+	}
+
+	var response openMeteoGeocodingResponse
+	if err := getJSON(ctx, o.Client, geocodingURL+"/v1/search?"+query.Encode(), &response); err != nil {
+		return nil, fmt.Errorf("geocoding %s: %w", name, err)
+	}
+
+	return response.Results, nil
+}
+
+// describeWeatherCode turns a WMO weather interpretation code into the
+// kind of description OpenWeatherMap returns.
+func describeWeatherCode(code int) string {
+	switch code {
+	case 0:
+		return "clear sky"
+	case 1:
+		return "mainly clear"
+	case 2:
+		return "partly cloudy"
+	case 3:
+		return "overcast clouds"
+	case 45, 48:
+		return "fog"
+	case 51, 53, 55:
+		return "drizzle"
+	case 56, 57:
+		return "freezing drizzle"
+	case 61:
+		return "light rain"
+	case 63:
+		return "moderate rain"
+	case 65:
+		return "heavy rain"
+	case 66, 67:
+		return "freezing rain"
+	case 71:
+		return "light snow"
+	case 73:
+		return "moderate snow"
+	case 75:
+		return "heavy snow"
+	case 77:
+		return "snow grains"
+	case 80, 81, 82:
+		return "rain showers"
+	case 85, 86:
+		return "snow showers"
+	case 95:
+		return "thunderstorm"
+	case 96, 99:
+		return "thunderstorm with hail"
+	default:
+		return "unknown conditions"
+	}
+}
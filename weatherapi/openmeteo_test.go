@@ -0,0 +1,62 @@
+package weatherapi_test
+
+import (
+	"KevinGo/weatherapi/weathertest"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestOpenMeteoGeocode(t *testing.T) {
+	srv := weathertest.NewServer()
+	defer srv.Close()
+
+	places, err := srv.OpenMeteo().Geocode(context.Background(), "New York", 10)
+	if err != nil {
+		t.Fatalf("Geocode: %v", err)
+	}
+	if len(places) != 2 || places[0].String() != "New York, United States" || places[0].Population == 0 {
+		t.Errorf("places = %v, want the recorded New York results", places)
+	}
+
+	// The name must be escaped, not cut at the space.
+	var escaped bool
+	for _, uri := range srv.Requests() {
+		if strings.HasPrefix(uri, "/v1/search?") && strings.Contains(uri, "name=New+York") {
+			escaped = true
+		}
+	}
+	if !escaped {
+		t.Errorf("requests = %q, want name=New+York", srv.Requests())
+	}
+
+	places, err = srv.OpenMeteo().Geocode(context.Background(), "Atlantis", 10)
+	if err != nil || len(places) != 0 {
+		t.Errorf("unknown name: places = %v, err = %v, want none and no error", places, err)
+	}
+}
+
+func TestOpenMeteoGetForecast(t *testing.T) {
+	srv := weathertest.NewServer()
+	defer srv.Close()
+
+	forecast, err := srv.OpenMeteo().GetForecast(context.Background(), "Cluj")
+	if err != nil {
+		t.Fatalf("GetForecast: %v", err)
+	}
+
+	if forecast.Location != "Cluj-Napoca" || len(forecast.Slots) < 24 {
+		t.Fatalf("forecast = %s with %d slots, want Cluj-Napoca with hourly slots", forecast.Location, len(forecast.Slots))
+	}
+
+	current := forecast.Current()
+	if current.Temperature != 14.8 || current.Description != "partly cloudy" || current.Humidity != 63 {
+		t.Errorf("current = %+v, want the recorded current conditions", current)
+	}
+	if got := current.Time.Format("2006-01-02 15:04 -0700"); got != "2026-10-18 15:00 +0300" {
+		t.Errorf("current time = %s, want 2026-10-18 15:00 +0300", got)
+	}
+	if next := forecast.Slots[1]; next.Time.Sub(current.Time).Hours() != 1 || next.Temperature != 15.8 {
+		t.Errorf("next slot = %+v, want the 16:00 hour", next)
+	}
+}
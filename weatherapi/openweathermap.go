@@ -0,0 +1,118 @@
+package weatherapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const openWeatherMapURL = "https://api.openweathermap.org"
+
+// OpenWeatherMap uses the 5 day / 3 hour forecast API, which needs a key.
+type OpenWeatherMap struct {
+	APIKey string
+	// BaseURL defaults to the public API.
+	BaseURL string
+	Client  *http.Client
+}
+
+type OpenWeatherResponse struct {
+	List []struct {
+		Dt   int64 `json:"dt"`
+		Main struct {
+			Temp     float32 `json:"temp"`
+			Humidity float32 `json:"humidity"`
+		} `json:"main"`
+		Weather []struct {
+			Description string `json:"description"`
+		} `json:"weather"`
+		Wind struct {
+			Speed float32 `json:"speed"`
+		} `json:"wind"`
+		Rain struct {
+			ThreeH float32 `json:"3h"`
+		} `json:"rain"`
+		Pop   float32 `json:"pop"`
+		DtTxt string  `json:"dt_txt"`
+	} `json:"list"`
+	City struct {
+		Name string `json:"name"`
+		// Timezone is the shift from UTC in seconds.
+		Timezone int `json:"timezone"`
+	} `json:"city"`
+}
+
+func (o *OpenWeatherMap) Name() string {
+	return "OpenWeatherMap"
+}
+
+// GetForecast returns the 3-hour slots of the next five days.
+func (o *OpenWeatherMap) GetForecast(ctx context.Context, city string) (*Forecast, error) {
+	return o.forecast(ctx, url.Values{"q": {city}}, city)
+}
+
+// ForecastFor looks the place up by its coordinates, which avoids
+// OpenWeatherMap's own guess for ambiguous names.
+func (o *OpenWeatherMap) ForecastFor(ctx context.Context, place Place) (*Forecast, error) {
+	forecast, err := o.forecast(ctx, url.Values{
+		"lat": {strconv.FormatFloat(place.Latitude, 'f', 4, 64)},
+		"lon": {strconv.FormatFloat(place.Longitude, 'f', 4, 64)},
+	}, place.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	forecast.Location = place.Name
+	for i := range forecast.Slots {
+		forecast.Slots[i].Location = place.Name
+	}
+	return forecast, nil
+}
+
+func (o *OpenWeatherMap) forecast(ctx context.Context, query url.Values, city string) (*Forecast, error) {
+	baseURL := o.BaseURL
+	if baseURL == "" {
+		baseURL = openWeatherMapURL
+	}
+
+	query.Set("appid", o.APIKey)
+	query.Set("units", "metric")
+	apiURL := baseURL + "/data/2.5/forecast?" + query.Encode()
+
+	var apiResponse OpenWeatherResponse
+	if err := getJSON(ctx, o.Client, apiURL, &apiResponse); err != nil {
+		return nil, err
+	}
+
+	if len(apiResponse.List) == 0 {
+		return nil, fmt.Errorf("no weather data found for city %s", city)
+	}
+
+	location := time.FixedZone(apiResponse.City.Name, apiResponse.City.Timezone)
+	forecast := &Forecast{Location: apiResponse.City.Name}
+
+	for _, entry := range apiResponse.List {
+		slotTime := time.Unix(entry.Dt, 0).In(location)
+		slot := WeatherData{
+			Time:          slotTime,
+			Day:           slotTime.Format(time.DateTime),
+			Location:      apiResponse.City.Name,
+			Temperature:   entry.Main.Temp,
+			Wind:          entry.Wind.Speed,
+			Humidity:      entry.Main.Humidity,
+			Precipitation: entry.Rain.ThreeH,
+			RainChance:    entry.Pop * 100,
+		}
+
+		if len(entry.Weather) > 0 {
+			slot.Description = entry.Weather[0].Description
+		}
+
+		forecast.Slots = append(forecast.Slots, slot)
+	}
+
+	return forecast, nil
+}
@@ -0,0 +1,56 @@
+package weatherapi_test
+
+import (
+	"KevinGo/apierror"
+	"KevinGo/weatherapi/weathertest"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestOpenWeatherMapGetForecast(t *testing.T) {
+	srv := weathertest.NewServer()
+	defer srv.Close()
+
+	forecast, err := srv.OpenWeatherMap().GetForecast(context.Background(), "Cluj-Napoca")
+	if err != nil {
+		t.Fatalf("GetForecast: %v", err)
+	}
+
+	if forecast.Location != "Cluj-Napoca" || len(forecast.Slots) != 40 {
+		t.Fatalf("forecast = %s with %d slots, want Cluj-Napoca with 40", forecast.Location, len(forecast.Slots))
+	}
+
+	current := forecast.Current()
+	if current.Temperature != 14.34 || current.Description != "few clouds" || current.Humidity != 67 || current.Wind != 2.1 {
+		t.Errorf("current = %+v, want the first recorded slot", current)
+	}
+	// The recording is for UTC+3, which the slots must be shown in.
+	if got := current.Time.Format("2006-01-02 15:04 -0700"); got != "2026-10-18 15:00 +0300" {
+		t.Errorf("current time = %s, want 2026-10-18 15:00 +0300", got)
+	}
+	for i := 1; i < len(forecast.Slots); i++ {
+		if !forecast.Slots[i].Time.After(forecast.Slots[i-1].Time) {
+			t.Fatalf("slot %d is not after slot %d", i, i-1)
+		}
+	}
+}
+
+func TestOpenWeatherMapErrors(t *testing.T) {
+	srv := weathertest.NewServer()
+	defer srv.Close()
+
+	provider := srv.OpenWeatherMap()
+	provider.APIKey = "wrong"
+	_, err := provider.GetForecast(context.Background(), "Cluj-Napoca")
+	if !errors.Is(err, apierror.ErrAuth) {
+		t.Errorf("wrong key: err = %v, want ErrAuth", err)
+	}
+
+	_, err = srv.OpenWeatherMap().GetForecast(context.Background(), "Atlantis")
+	var statusErr *apierror.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound || statusErr.Message != "city not found" {
+		t.Errorf("unknown city: err = %v, want a 404 StatusError saying city not found", err)
+	}
+}
@@ -1,6 +1,8 @@
 package weatherapi
 
 import (
+	"KevinGo/apierror"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -8,94 +10,119 @@ import (
 	"time"
 )
 
+// WeatherData is the weather for one forecast slot.
 type WeatherData struct {
-	Day           string  `json:"day"`
-	Location      string  `json:"location"`
-	Temperature   float32 `json:"temperature"`
-	Description   string  `json:"description"`
-	Precipitation float32 `json:"precipitation"`
-	Wind          float32 `json:"wind"`
-	Humidity      float32 `json:"humidity"`
+	// Time is the start of the slot in the location's time zone.
+	Time          time.Time `json:"time"`
+	Day           string    `json:"day"`
+	Location      string    `json:"location"`
+	Temperature   float32   `json:"temperature"`
+	Description   string    `json:"description"`
+	Precipitation float32   `json:"precipitation"`
+	Wind          float32   `json:"wind"`
+	Humidity      float32   `json:"humidity"`
+	// RainChance is the probability of precipitation in percent.
+	RainChance float32 `json:"rain_chance"`
 }
 
-type OpenWeatherResponse struct {
-	List []struct {
-		Main struct {
-			Temp     float32 `json:"temp"`
-			Humidity float32 `json:"humidity"`
-		} `json:"main"`
-		Weather []struct {
-			Description string `json:"description"`
-		} `json:"weather"`
-		Wind struct {
-			Speed float32 `json:"speed"`
-		} `json:"wind"`
-		Rain struct {
-			ThreeH float32 `json:"3h"`
-		} `json:"rain"`
-		DtTxt string `json:"dt_txt"`
-	} `json:"list"`
-	City struct {
-		Name string `json:"name"`
-	} `json:"city"`
+// Forecast is the weather series for one place. The first slot holds the
+// current or nearest conditions and the rest follow in chronological
+// order.
+type Forecast struct {
+	Location string
+	Slots    []WeatherData
 }
 
-func GetWeather(city string) (*WeatherData, error) {
-	apiKey := "your_key"
-	apiURL := fmt.Sprintf("https://api.openweathermap.org/data/2.5/forecast?q=%s&appid=%s&units=metric", city, apiKey)
+// Current returns the first slot.
+func (f *Forecast) Current() WeatherData {
+	return f.Slots[0]
+}
 
-	client := &http.Client{
-		Timeout: 10 * time.Second,
+// Between returns the slots that start in [start, end).
+func (f *Forecast) Between(start, end time.Time) []WeatherData {
+	var slots []WeatherData
+	for _, slot := range f.Slots {
+		if !slot.Time.Before(start) && slot.Time.Before(end) {
+			slots = append(slots, slot)
+		}
 	}
+	return slots
+}
 
-	req, err := http.NewRequest("GET", apiURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error creating request: %w", err)
-	}
+// End returns when the last slot starts.
+func (f *Forecast) End() time.Time {
+	return f.Slots[len(f.Slots)-1].Time
+}
 
-	req.Header.Set("Content-Type", "application/json")
+// WeatherProvider looks up the forecast for a city, or for a place that
+// was already geocoded.
+type WeatherProvider interface {
+	Name() string
+	GetForecast(ctx context.Context, city string) (*Forecast, error)
+	ForecastFor(ctx context.Context, place Place) (*Forecast, error)
+}
 
-	res, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("error calling API: %w", err)
+// Provider answers GetForecast. It is replaced from the configuration at
+// startup.
+var Provider WeatherProvider = &OpenMeteo{}
+
+// NewProvider returns the provider called name: "openweathermap",
+// "openmeteo", or "" for OpenWeatherMap when apiKey is set and the keyless
+// Open-Meteo otherwise.
+func NewProvider(name, apiKey string) (WeatherProvider, error) {
+	switch name {
+	case "":
+		if apiKey != "" {
+			return &OpenWeatherMap{APIKey: apiKey}, nil
+		}
+		return &OpenMeteo{}, nil
+	case "openweathermap":
+		return &OpenWeatherMap{APIKey: apiKey}, nil
+	case "openmeteo":
+		return &OpenMeteo{}, nil
+	default:
+		return nil, fmt.Errorf("unknown weather provider %q", name)
 	}
-	defer res.Body.Close()
+}
 
-	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned status: %d", res.StatusCode)
+func GetForecast(city string) (*Forecast, error) {
+	return Provider.GetForecast(context.Background(), city)
+}
+
+var defaultClient = &http.Client{
+	Timeout: 10 * time.Second,
+}
+
+// getJSON fetches apiURL and decodes the JSON body into v. HTTP failures
+// are *apierror.StatusError values.
+func getJSON(ctx context.Context, client *http.Client, apiURL string, v interface{}) error {
+	if client == nil {
+		client = defaultClient
 	}
 
-	body, err := io.ReadAll(res.Body)
+	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
 	if err != nil {
-		return nil, fmt.Errorf("error reading response: %w", err)
+		return fmt.Errorf("error creating request: %w", err)
 	}
 
-	var apiResponse OpenWeatherResponse
-	err = json.Unmarshal(body, &apiResponse)
+	res, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing JSON: %w", err)
+		return apierror.Transport(req.URL.Host, err)
 	}
+	defer res.Body.Close()
 
-	if len(apiResponse.List) == 0 {
-		return nil, fmt.Errorf("no weather data found for city %s", city)
+	if err := apierror.Check(req.URL.Host, res); err != nil {
+		return err
 	}
 
-	firstForecast := apiResponse.List[0]
-
-	weather := &WeatherData{
-		Day:         firstForecast.DtTxt,
-		Location:    apiResponse.City.Name,
-		Temperature: firstForecast.Main.Temp,
-		Description: "",
-		Wind:        firstForecast.Wind.Speed,
-		Humidity:    firstForecast.Main.Humidity,
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("error reading response: %w", err)
 	}
 
-	if len(firstForecast.Weather) > 0 {
-		weather.Description = firstForecast.Weather[0].Description
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("error parsing JSON: %w", err)
 	}
 
-	weather.Precipitation = firstForecast.Rain.ThreeH
-
-	return weather, nil
+	return nil
 }
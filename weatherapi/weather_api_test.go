@@ -0,0 +1,47 @@
+package weatherapi_test
+
+import (
+	"KevinGo/apierror"
+	"KevinGo/weatherapi"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStatusErrors(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		want   error
+	}{
+		{http.StatusUnauthorized, `{"cod":401, "message": "Invalid API key"}`, apierror.ErrAuth},
+		{http.StatusTooManyRequests, `{"error":true,"reason":"Daily API request limit exceeded"}`, apierror.ErrRateLimited},
+		{http.StatusServiceUnavailable, "", apierror.ErrUnavailable},
+	}
+
+	for _, tt := range tests {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+			w.Write([]byte(tt.body))
+		}))
+
+		providers := []weatherapi.WeatherProvider{
+			&weatherapi.OpenWeatherMap{APIKey: "key", BaseURL: srv.URL},
+			&weatherapi.OpenMeteo{BaseURL: srv.URL, GeocodingURL: srv.URL},
+		}
+		for _, provider := range providers {
+			_, err := provider.GetForecast(context.Background(), "Cluj")
+			var statusErr *apierror.StatusError
+			if !errors.As(err, &statusErr) || statusErr.StatusCode != tt.status {
+				t.Errorf("%s, status %d: err = %v, want a StatusError", provider.Name(), tt.status, err)
+			}
+			if !errors.Is(err, tt.want) {
+				t.Errorf("%s, status %d: err = %v, want %v", provider.Name(), tt.status, err, tt.want)
+			}
+		}
+
+		srv.Close()
+	}
+}
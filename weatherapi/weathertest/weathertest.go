@@ -0,0 +1,140 @@
+// Package weathertest serves responses recorded from OpenWeatherMap and
+// Open-Meteo, so the weather providers can be exercised without network
+// access or an API key.
+//
+//	srv := weathertest.NewServer()
+//	defer srv.Close()
+//	forecast, err := srv.OpenMeteo().GetForecast(ctx, "Cluj")
+//
+// Geocoding knows Cluj, Paris (five of them), Springfield (ambiguous), New
+// York and Brasov (spelled without diacritics, like some geocoders
+// expect). Forecasts by name only know Cluj-Napoca; forecasts by
+// coordinates answer with the Cluj-Napoca recording for any place. Other
+// names are answered the way the real services answer unknown names.
+package weathertest
+
+import (
+	"KevinGo/weatherapi"
+	"embed"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// APIKey is the only OpenWeatherMap key the stand-in accepts.
+const APIKey = "test-key"
+
+//go:embed fixtures/*.json
+var fixtures embed.FS
+
+// geocoding maps lowercase search names to recorded geocoding answers.
+var geocoding = map[string]string{
+	"cluj":        "openmeteo_geocoding_cluj.json",
+	"cluj-napoca": "openmeteo_geocoding_cluj.json",
+	"paris":       "openmeteo_geocoding_paris.json",
+	"springfield": "openmeteo_geocoding_springfield.json",
+	"new york":    "openmeteo_geocoding_new_york.json",
+	"brasov":      "openmeteo_geocoding_brasov.json",
+}
+
+// Server answers the forecast endpoints of both providers.
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []string
+}
+
+func NewServer() *Server {
+	s := &Server{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/data/2.5/forecast", s.openWeatherMapForecast)
+	mux.HandleFunc("/v1/search", s.openMeteoSearch)
+	mux.HandleFunc("/v1/forecast", s.openMeteoForecast)
+	s.Server = httptest.NewServer(s.record(mux))
+
+	return s
+}
+
+// OpenWeatherMap returns a provider pointed at the stand-in.
+func (s *Server) OpenWeatherMap() *weatherapi.OpenWeatherMap {
+	return &weatherapi.OpenWeatherMap{APIKey: APIKey, BaseURL: s.URL, Client: s.Client()}
+}
+
+// OpenMeteo returns a provider pointed at the stand-in.
+func (s *Server) OpenMeteo() *weatherapi.OpenMeteo {
+	return &weatherapi.OpenMeteo{BaseURL: s.URL, GeocodingURL: s.URL, Client: s.Client()}
+}
+
+// Requests returns the raw request URIs received so far, to check how
+// parameters were encoded.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+func (s *Server) record(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.RequestURI)
+		s.mu.Unlock()
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) openWeatherMapForecast(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if query.Get("appid") != APIKey {
+		writeJSON(w, http.StatusUnauthorized, `{"cod":401, "message": "Invalid API key. Please see https://openweathermap.org/faq#error401 for more info."}`)
+		return
+	}
+
+	if query.Get("lat") != "" && query.Get("lon") != "" {
+		serveFixture(w, "openweathermap_forecast.json")
+		return
+	}
+
+	switch strings.ToLower(query.Get("q")) {
+	case "cluj", "cluj-napoca", "cluj-napoca,ro":
+		serveFixture(w, "openweathermap_forecast.json")
+	default:
+		writeJSON(w, http.StatusNotFound, `{"cod":"404","message":"city not found"}`)
+	}
+}
+
+func (s *Server) openMeteoSearch(w http.ResponseWriter, r *http.Request) {
+	name, ok := geocoding[strings.ToLower(r.URL.Query().Get("name"))]
+	if !ok {
+		writeJSON(w, http.StatusOK, `{"generationtime_ms":0.27}`)
+		return
+	}
+	serveFixture(w, name)
+}
+
+func (s *Server) openMeteoForecast(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("latitude") == "" || query.Get("longitude") == "" {
+		writeJSON(w, http.StatusBadRequest, `{"error":true,"reason":"Parameter 'latitude' and 'longitude' must have the same number of elements"}`)
+		return
+	}
+	serveFixture(w, "openmeteo_forecast.json")
+}
+
+func serveFixture(w http.ResponseWriter, name string) {
+	data, err := fixtures.ReadFile("fixtures/" + name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, string(data))
+}
+
+func writeJSON(w http.ResponseWriter, status int, body string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	w.Write([]byte(body))
+}